@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/NordCoder/Story/config"
+	"github.com/NordCoder/Story/internal/infrastructure/redis"
+	"go.uber.org/zap"
+)
+
+const factsUsage = `usage:
+  story facts export -o facts.jsonl   выгрузить факты, feed_queue и category_set:* из Redis
+  story facts import -i facts.jsonl   загрузить дамп; истёкшие и уже существующие факты пропускаются`
+
+// runFacts выполняет подкоманду "facts": выгрузку и загрузку кэша фактов в формате JSON Lines.
+func runFacts(args []string, log *zap.Logger) error {
+	if len(args) == 0 {
+		return errors.New(factsUsage)
+	}
+
+	fs := flag.NewFlagSet("facts "+args[0], flag.ContinueOnError)
+	output := fs.String("o", "", "файл для выгрузки")
+	input := fs.String("i", "", "файл для загрузки")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	client, err := redis.NewRedisClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	storageCfg, err := config.NewStorageConfig()
+	if err != nil {
+		return err
+	}
+	ttl, err := time.ParseDuration(storageCfg.FactTTL)
+	if err != nil {
+		return fmt.Errorf("invalid fact_ttl: %w", err)
+	}
+	repo := redis.NewFactRepository(client, ttl, redis.WithHashTag(config.NewRedisConfig().HashTag))
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "export":
+		// stdout занят логами, поэтому дамп пишется только в файл
+		if *output == "" {
+			return errors.New(factsUsage)
+		}
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		report, err := repo.Export(ctx, f)
+		if err != nil {
+			return err
+		}
+		log.Info("facts exported",
+			zap.Int("facts", report.Facts),
+			zap.Int("queue", report.Queue),
+			zap.Int("categories", report.Categories),
+		)
+	case "import":
+		if *input == "" {
+			return errors.New(factsUsage)
+		}
+		f, err := os.Open(*input)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		report, err := repo.Import(ctx, f)
+		if err != nil {
+			return err
+		}
+		log.Info("facts imported",
+			zap.Int("facts", report.Facts),
+			zap.Int("queue", report.Queue),
+			zap.Int("categories", report.Categories),
+			zap.Int("skipped", report.Skipped),
+		)
+	default:
+		return errors.New(factsUsage)
+	}
+	return nil
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"os"
+
 	"github.com/NordCoder/Story/config"
 	"github.com/NordCoder/Story/internal/app"
 	"github.com/NordCoder/Story/internal/logger"
@@ -22,6 +24,13 @@ func main() {
 		}
 	}(log)
 
+	if len(os.Args) > 1 && os.Args[1] == "facts" {
+		if err := runFacts(os.Args[2:], log); err != nil {
+			log.Fatal("facts command failed", zap.Error(err))
+		}
+		return
+	}
+
 	httpCfg := config.NewHTTPConfig()
 
 	app.Run(httpCfg, log)
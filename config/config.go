@@ -2,16 +2,38 @@ package config
 
 import (
 	"fmt"
+	"net/url"
+	"time"
 
 	"github.com/spf13/viper"
+	"go.uber.org/zap/zapcore"
 )
 
 const (
-	httpConfigPath   = "config/http.yaml"
-	loggerConfigPath = "config/logger.yaml"
-	redisConfigPath  = "config/redis.yaml"
+	HTTPConfigPath    = "config/http.yaml"
+	LoggerConfigPath  = "config/logger.yaml"
+	RedisConfigPath   = "config/redis.yaml"
+	FeedConfigPath    = "config/feed.yaml"
+	StorageConfigPath = "config/storage.yaml"
+	// ExperimentsConfigPath — A/B-эксперименты над параметрами выдачи.
+	ExperimentsConfigPath = "config/experiments.yaml"
 )
 
+// ReadKey читает секцию key из YAML-файла в out через отдельный экземпляр viper,
+// чтобы загрузчики разных файлов не перетирали друг другу глобальное состояние.
+// Через него читают конфиги и загрузчики сервисов.
+func ReadKey(path, key string, out interface{}) error {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("fatal error config file: %w", err)
+	}
+	if err := v.UnmarshalKey(key, out); err != nil {
+		return fmt.Errorf("cannot parse %s config: %w", key, err)
+	}
+	return nil
+}
+
 // -- HTTP config ---------------------------------------------------------------------------------
 
 type HTTPConfig struct {
@@ -51,16 +73,36 @@ type HTTPConfig struct {
 }
 
 func NewHTTPConfig() *HTTPConfig {
-	viper.SetConfigFile(httpConfigPath)
-	if err := viper.ReadInConfig(); err != nil {
-		panic(fmt.Errorf("fatal error config file: %w", err))
+	httpCfg, err := LoadHTTPConfig()
+	if err != nil {
+		panic(err)
 	}
+	return httpCfg
+}
+
+// LoadHTTPConfig читает и валидирует http.yaml; используется и при горячей перезагрузке.
+func LoadHTTPConfig() (*HTTPConfig, error) {
 	var httpCfg HTTPConfig
-	if err := viper.UnmarshalKey("http", &httpCfg); err != nil {
-		panic(fmt.Errorf("cannot parse http config: %w", err))
+	if err := ReadKey(HTTPConfigPath, "http", &httpCfg); err != nil {
+		return nil, err
+	}
+	if err := httpCfg.Validate(); err != nil {
+		return nil, err
 	}
+	return &httpCfg, nil
+}
 
-	return &httpCfg
+func (c *HTTPConfig) Validate() error {
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" {
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid CORS origin %q", origin)
+		}
+	}
+	return nil
 }
 
 // -- LOGGER config ---------------------------------------------------------------------------------
@@ -79,26 +121,60 @@ type LoggerConfig struct {
 }
 
 func NewLoggerConfig() *LoggerConfig {
-	viper.SetConfigFile("config/logger.yaml")
-	if err := viper.ReadInConfig(); err != nil {
-		panic(fmt.Errorf("fatal error config file: %w", err))
+	loggerCfg, err := LoadLoggerConfig()
+	if err != nil {
+		panic(err)
 	}
+	return loggerCfg
+}
+
+// LoadLoggerConfig читает и валидирует logger.yaml; используется и при горячей перезагрузке.
+func LoadLoggerConfig() (*LoggerConfig, error) {
 	var loggerCfg LoggerConfig
-	if err := viper.UnmarshalKey("logger", &loggerCfg); err != nil {
-		panic(fmt.Errorf("cannot parse logger config: %w", err))
+	if err := ReadKey(LoggerConfigPath, "logger", &loggerCfg); err != nil {
+		return nil, err
+	}
+	if err := loggerCfg.Validate(); err != nil {
+		return nil, err
 	}
+	return &loggerCfg, nil
+}
 
-	return &loggerCfg
+func (c *LoggerConfig) Validate() error {
+	var level zapcore.Level
+	if err := level.UnmarshalText([]byte(c.Level)); err != nil {
+		return fmt.Errorf("invalid log level %q: %w", c.Level, err)
+	}
+	return nil
 }
 
 // -- REDIS ---------------------------------------------------------------------------------------
 
+const (
+	RedisModeSingle   = "single"
+	RedisModeSentinel = "sentinel"
+	RedisModeCluster  = "cluster"
+)
+
 type RedisConfig struct {
+	// Mode — single (host:port), sentinel (failover через master_name) или cluster.
+	Mode string `mapstructure:"mode"`
+
 	Host     string `mapstructure:"host"`
 	Port     int    `mapstructure:"port"`
 	Password string `mapstructure:"password"`
 	DB       int    `mapstructure:"db"`
 
+	// Addrs — адреса sentinel'ей в режиме sentinel или seed-узлы кластера в режиме cluster.
+	Addrs            []string `mapstructure:"addrs"`
+	MasterName       string   `mapstructure:"master_name"`
+	SentinelPassword string   `mapstructure:"sentinel_password"`
+
+	// HashTag оборачивает ключи фактов, очереди и индексов категорий в {tag}: Lua-скрипты
+	// репозитория фактов трогают их вместе, и в кластере они должны быть в одном слоте.
+	// Остальные сторы тег не используют. Пустой — ключи без тега.
+	HashTag string `mapstructure:"hash_tag"`
+
 	DialTimeout  string `mapstructure:"dial_timeout"`
 	ReadTimeout  string `mapstructure:"read_timeout"`
 	WriteTimeout string `mapstructure:"write_timeout"`
@@ -108,17 +184,225 @@ type RedisConfig struct {
 	PoolTimeout  string `mapstructure:"pool_timeout"`
 
 	PingTimeout string `mapstructure:"ping_timeout"`
+
+	// GC — фоновая очистка очереди и индексов от ID истёкших фактов.
+	GC struct {
+		Enabled   bool   `mapstructure:"enabled"`
+		Interval  string `mapstructure:"interval"`
+		BatchSize int    `mapstructure:"batch_size"`
+	} `mapstructure:"gc"`
 }
 
 func NewRedisConfig() *RedisConfig {
-	viper.SetConfigFile("config/redis.yaml")
-	if err := viper.ReadInConfig(); err != nil {
-		panic(fmt.Errorf("fatal error config file: %w", err))
-	}
 	var redisCfg RedisConfig
-	if err := viper.UnmarshalKey("redis", &redisCfg); err != nil {
-		panic(fmt.Errorf("cannot parse redis config: %w", err))
+	if err := ReadKey(RedisConfigPath, "redis", &redisCfg); err != nil {
+		panic(err)
+	}
+	if err := redisCfg.Validate(); err != nil {
+		panic(err)
 	}
 
 	return &redisCfg
 }
+
+func (c *RedisConfig) Validate() error {
+	switch c.Mode {
+	case "", RedisModeSingle:
+	case RedisModeSentinel:
+		if len(c.Addrs) == 0 || c.MasterName == "" {
+			return fmt.Errorf("redis sentinel mode requires addrs and master_name")
+		}
+	case RedisModeCluster:
+		if len(c.Addrs) == 0 {
+			return fmt.Errorf("redis cluster mode requires addrs")
+		}
+		if c.DB != 0 {
+			return fmt.Errorf("redis cluster supports only db 0")
+		}
+		if c.HashTag == "" {
+			return fmt.Errorf("redis cluster mode requires hash_tag")
+		}
+	default:
+		return fmt.Errorf("unknown redis mode %q", c.Mode)
+	}
+	return nil
+}
+
+// -- FEED ----------------------------------------------------------------------------------------
+
+const (
+	FeedStrategyCategory = "category"
+	FeedStrategyRandom   = "random"
+)
+
+type FeedConfig struct {
+	// StrategyWeights — веса способов выбора факта в GetFact: category (по рекомендациям) и random.
+	StrategyWeights map[string]float64 `mapstructure:"strategy_weights"`
+	Related         RelatedConfig      `mapstructure:"related"`
+}
+
+// RelatedConfig — подбор «похожих фактов» к карточке (GetRelatedFacts).
+type RelatedConfig struct {
+	DefaultLimit int `mapstructure:"default_limit"`
+	MaxLimit     int `mapstructure:"max_limit"`
+	// Budget — сколько запрос может ждать Википедию; что не успело, в ответ не попадает.
+	Budget time.Duration `mapstructure:"budget"`
+	// ParentCategories и Siblings ограничивают поиск соседних подкатегорий: родители категории
+	// факта и их подкатегории.
+	ParentCategories int `mapstructure:"parent_categories"`
+	Siblings         int `mapstructure:"siblings"`
+	// PerCategory — сколько фактов брать из каждой категории инвентаря.
+	PerCategory int `mapstructure:"per_category"`
+}
+
+func (c *RelatedConfig) Validate() error {
+	if c.DefaultLimit <= 0 || c.MaxLimit < c.DefaultLimit {
+		return fmt.Errorf("feed.related: need 0 < default_limit <= max_limit")
+	}
+	if c.Budget <= 0 {
+		return fmt.Errorf("feed.related: budget must be positive")
+	}
+	if c.ParentCategories < 0 || c.Siblings < 0 || c.PerCategory <= 0 {
+		return fmt.Errorf("feed.related: limits must not be negative, per_category must be positive")
+	}
+	return nil
+}
+
+func NewFeedConfig() (*FeedConfig, error) {
+	var feedCfg FeedConfig
+	if err := ReadKey(FeedConfigPath, "feed", &feedCfg); err != nil {
+		return nil, err
+	}
+
+	for name := range feedCfg.StrategyWeights {
+		if name != FeedStrategyCategory && name != FeedStrategyRandom {
+			return nil, fmt.Errorf("unknown feed strategy %q", name)
+		}
+	}
+	if err := feedCfg.Related.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &feedCfg, nil
+}
+
+// -- EXPERIMENTS ---------------------------------------------------------------------------------
+
+type ExperimentsConfig struct {
+	Experiments []ExperimentConfig `mapstructure:"experiments"`
+}
+
+// ExperimentConfig — эксперимент: пользователи детерминированно делятся между вариантами
+// пропорционально traffic по хэшу имени эксперимента и UserID.
+type ExperimentConfig struct {
+	Name     string          `mapstructure:"name"`
+	Enabled  bool            `mapstructure:"enabled"`
+	Variants []VariantConfig `mapstructure:"variants"`
+}
+
+// VariantConfig — вариант эксперимента и параметры, которые он переопределяет.
+type VariantConfig struct {
+	Name    string  `mapstructure:"name"`
+	Traffic float64 `mapstructure:"traffic"`
+	// StrategyWeights заменяет feed.strategy_weights для пользователей варианта; пусто — без изменений.
+	StrategyWeights map[string]float64 `mapstructure:"strategy_weights"`
+}
+
+func LoadExperimentsConfig() (*ExperimentsConfig, error) {
+	var cfg ExperimentsConfig
+	if err := ReadKey(ExperimentsConfigPath, "experiments", &cfg.Experiments); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Validate проверяет имена и доли вариантов. Один параметр может переопределять только один
+// включённый эксперимент, иначе пересекающиеся эксперименты исказят друг другу результаты.
+func (c *ExperimentsConfig) Validate() error {
+	names := make(map[string]struct{}, len(c.Experiments))
+	strategyOwner := ""
+	for _, e := range c.Experiments {
+		if e.Name == "" {
+			return fmt.Errorf("experiments: experiment name is required")
+		}
+		if _, ok := names[e.Name]; ok {
+			return fmt.Errorf("experiments: duplicate experiment %q", e.Name)
+		}
+		names[e.Name] = struct{}{}
+
+		if len(e.Variants) == 0 {
+			return fmt.Errorf("experiments: %q has no variants", e.Name)
+		}
+		variants := make(map[string]struct{}, len(e.Variants))
+		var traffic float64
+		overridesStrategy := false
+		for _, v := range e.Variants {
+			if v.Name == "" {
+				return fmt.Errorf("experiments: %q has a variant without a name", e.Name)
+			}
+			if _, ok := variants[v.Name]; ok {
+				return fmt.Errorf("experiments: %q has duplicate variant %q", e.Name, v.Name)
+			}
+			variants[v.Name] = struct{}{}
+			if v.Traffic < 0 {
+				return fmt.Errorf("experiments: %q/%q has negative traffic", e.Name, v.Name)
+			}
+			traffic += v.Traffic
+			for name := range v.StrategyWeights {
+				if name != FeedStrategyCategory && name != FeedStrategyRandom {
+					return fmt.Errorf("experiments: %q/%q: unknown feed strategy %q", e.Name, v.Name, name)
+				}
+			}
+			overridesStrategy = overridesStrategy || len(v.StrategyWeights) > 0
+		}
+		if traffic <= 0 {
+			return fmt.Errorf("experiments: %q has no traffic", e.Name)
+		}
+
+		if e.Enabled && overridesStrategy {
+			if strategyOwner != "" {
+				return fmt.Errorf("experiments: %q and %q both override strategy_weights", strategyOwner, e.Name)
+			}
+			strategyOwner = e.Name
+		}
+	}
+	return nil
+}
+
+// -- STORAGE -------------------------------------------------------------------------------------
+
+const (
+	StorageBackendRedis  = "redis"
+	StorageBackendMemory = "memory"
+)
+
+type StorageConfig struct {
+	// Backend — хранилище фактов: redis или memory.
+	Backend string `mapstructure:"backend"`
+	FactTTL string `mapstructure:"fact_ttl"`
+	// PurgeInterval — как часто memory-бэкенд удаляет истёкшие факты; Redis удаляет их сам.
+	PurgeInterval string `mapstructure:"purge_interval"`
+
+	// Archive — копия каждого факта в Postgres, над которой Backend служит кэшем.
+	Archive struct {
+		Enabled bool `mapstructure:"enabled"`
+	} `mapstructure:"archive"`
+}
+
+func NewStorageConfig() (*StorageConfig, error) {
+	var storageCfg StorageConfig
+	if err := ReadKey(StorageConfigPath, "storage", &storageCfg); err != nil {
+		return nil, err
+	}
+
+	switch storageCfg.Backend {
+	case StorageBackendRedis, StorageBackendMemory:
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", storageCfg.Backend)
+	}
+
+	return &storageCfg, nil
+}
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// ReloadFunc перечитывает файл, валидирует его и применяет изменения.
+// Ошибка означает, что изменение отклонено и в силе осталась прежняя конфигурация.
+type ReloadFunc func() error
+
+const defaultDebounce = 200 * time.Millisecond
+
+// configMapDataLink — симлинк, который kubelet переключает при обновлении ConfigMap.
+const configMapDataLink = "..data"
+
+// Watcher следит за YAML-файлами конфигурации и вызывает обработчики при их изменении.
+// Наблюдение ведётся за каталогами, а не за файлами: редакторы подменяют файл через rename,
+// после которого наблюдение за самим файлом теряется.
+//
+// ConfigMap в k8s обновляется иначе: файлы — это симлинки на ..data/<файл>, а kubelet атомарно
+// переключает симлинк ..data на новый каталог. Событие приходит на ..data, а не на файл,
+// поэтому оно перезагружает все отслеживаемые файлы этого каталога.
+type Watcher struct {
+	logger   *zap.Logger
+	debounce time.Duration
+	reloads  *prometheus.CounterVec
+
+	mu       sync.Mutex
+	handlers map[string][]ReloadFunc
+	timers   map[string]*time.Timer
+}
+
+type WatcherOption func(*Watcher)
+
+// WithDebounce задаёт паузу, после которой серия событий по файлу считается одним изменением.
+func WithDebounce(d time.Duration) WatcherOption {
+	return func(w *Watcher) { w.debounce = d }
+}
+
+// WithReloadMetrics регистрирует счётчик применённых и отклонённых перезагрузок.
+func WithReloadMetrics(reg prometheus.Registerer) WatcherOption {
+	return func(w *Watcher) {
+		w.reloads = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "wikifeed",
+				Subsystem: "config",
+				Name:      "reloads_total",
+				Help:      "Config reloads by file and result (applied/rejected)",
+			},
+			[]string{"file", "result"},
+		)
+		reg.MustRegister(w.reloads)
+	}
+}
+
+func NewWatcher(logger *zap.Logger, opts ...WatcherOption) *Watcher {
+	w := &Watcher{
+		logger:   logger,
+		debounce: defaultDebounce,
+		handlers: make(map[string][]ReloadFunc),
+		timers:   make(map[string]*time.Timer),
+	}
+	for _, o := range opts {
+		o(w)
+	}
+	return w
+}
+
+// OnChange регистрирует обработчик для файла. Должен вызываться до Run.
+func (w *Watcher) OnChange(path string, fn ReloadFunc) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	path = filepath.Clean(path)
+	w.handlers[path] = append(w.handlers[path], fn)
+}
+
+// Run блокируется до отмены ctx.
+func (w *Watcher) Run(ctx context.Context) error {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer fsw.Close()
+
+	w.mu.Lock()
+	dirs := make(map[string]struct{})
+	for path := range w.handlers {
+		dirs[filepath.Dir(path)] = struct{}{}
+	}
+	w.mu.Unlock()
+
+	for dir := range dirs {
+		if err := fsw.Add(dir); err != nil {
+			return err
+		}
+	}
+	w.logger.Info("config watcher started", zap.Int("dirs", len(dirs)))
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.stopTimers()
+			return ctx.Err()
+		case event, ok := <-fsw.Events:
+			if !ok {
+				return nil
+			}
+			if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) && !event.Has(fsnotify.Rename) {
+				continue
+			}
+			path := filepath.Clean(event.Name)
+			if filepath.Base(path) == configMapDataLink {
+				w.scheduleDir(filepath.Dir(path))
+				continue
+			}
+			w.schedule(path)
+		case err, ok := <-fsw.Errors:
+			if !ok {
+				return nil
+			}
+			w.logger.Error("config watcher error", zap.Error(err))
+		}
+	}
+}
+
+// scheduleDir планирует перезагрузку всех отслеживаемых файлов каталога dir.
+func (w *Watcher) scheduleDir(dir string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for path := range w.handlers {
+		if filepath.Dir(path) == dir {
+			w.scheduleLocked(path)
+		}
+	}
+}
+
+// schedule откладывает перезагрузку файла на debounce, сбрасывая уже запланированную.
+func (w *Watcher) schedule(path string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.scheduleLocked(path)
+}
+
+// scheduleLocked вызывается под w.mu.
+func (w *Watcher) scheduleLocked(path string) {
+	if _, ok := w.handlers[path]; !ok {
+		return
+	}
+	if t, ok := w.timers[path]; ok {
+		t.Reset(w.debounce)
+		return
+	}
+	w.timers[path] = time.AfterFunc(w.debounce, func() { w.reload(path) })
+}
+
+func (w *Watcher) reload(path string) {
+	w.mu.Lock()
+	delete(w.timers, path)
+	handlers := append([]ReloadFunc(nil), w.handlers[path]...)
+	w.mu.Unlock()
+
+	for _, fn := range handlers {
+		if err := fn(); err != nil {
+			w.logger.Error("config change rejected", zap.String("file", path), zap.Error(err))
+			w.count(path, "rejected")
+			continue
+		}
+		w.logger.Info("config change applied", zap.String("file", path))
+		w.count(path, "applied")
+	}
+}
+
+func (w *Watcher) count(path, result string) {
+	if w.reloads != nil {
+		w.reloads.WithLabelValues(path, result).Inc()
+	}
+}
+
+func (w *Watcher) stopTimers() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for path, t := range w.timers {
+		t.Stop()
+		delete(w.timers, path)
+	}
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// startWatcher запускает w и ждёт, пока он начнёт получать события.
+func startWatcher(t *testing.T, w *Watcher) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = w.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	// fsw.Add выполняется в начале Run
+	time.Sleep(100 * time.Millisecond)
+}
+
+func waitReload(t *testing.T, reloaded <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-reloaded:
+	case <-time.After(3 * time.Second):
+		t.Fatal("reload was not triggered")
+	}
+}
+
+func TestWatcherReloadsOnWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "feed.yaml")
+	if err := os.WriteFile(path, []byte("a: 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded := make(chan struct{}, 1)
+	w := NewWatcher(zap.NewNop(), WithDebounce(10*time.Millisecond))
+	w.OnChange(path, func() error {
+		reloaded <- struct{}{}
+		return nil
+	})
+	startWatcher(t, w)
+
+	if err := os.WriteFile(path, []byte("a: 2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitReload(t, reloaded)
+}
+
+// TestWatcherReloadsOnConfigMapSwap воспроизводит обновление ConfigMap kubelet'ом:
+// feed.yaml -> ..data/feed.yaml, ..data -> ..<версия>, обновление — атомарная подмена ..data.
+func TestWatcherReloadsOnConfigMapSwap(t *testing.T) {
+	dir := t.TempDir()
+	writeVersion := func(version, content string) {
+		t.Helper()
+		if err := os.Mkdir(filepath.Join(dir, version), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, version, "feed.yaml"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	swapData := func(version string) {
+		t.Helper()
+		tmp := filepath.Join(dir, "..data_tmp")
+		if err := os.Symlink(version, tmp); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(tmp, filepath.Join(dir, configMapDataLink)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeVersion("..v1", "a: 1\n")
+	swapData("..v1")
+	path := filepath.Join(dir, "feed.yaml")
+	if err := os.Symlink(filepath.Join(configMapDataLink, "feed.yaml"), path); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded := make(chan struct{}, 1)
+	w := NewWatcher(zap.NewNop(), WithDebounce(10*time.Millisecond))
+	w.OnChange(path, func() error {
+		reloaded <- struct{}{}
+		return nil
+	})
+	startWatcher(t, w)
+
+	writeVersion("..v2", "a: 2\n")
+	swapData("..v2")
+	waitReload(t, reloaded)
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "a: 2\n" {
+		t.Errorf("feed.yaml = %q after swap", got)
+	}
+}
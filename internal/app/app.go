@@ -8,9 +8,12 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
+	admincontroller "github.com/NordCoder/Story/services/admin/controller"
+	adminusecase "github.com/NordCoder/Story/services/admin/usecase"
 	controller3 "github.com/NordCoder/Story/services/recommendation/controller"
 	repository2 "github.com/NordCoder/Story/services/recommendation/repository"
 
@@ -20,10 +23,15 @@ import (
 	"github.com/NordCoder/Story/config"
 	storypb "github.com/NordCoder/Story/generated/api/proto/v1"
 	"github.com/NordCoder/Story/internal/controller"
+	"github.com/NordCoder/Story/internal/experiment"
+	"github.com/NordCoder/Story/internal/infrastructure"
+	"github.com/NordCoder/Story/internal/infrastructure/memory"
+	"github.com/NordCoder/Story/internal/infrastructure/postgres"
 	"github.com/NordCoder/Story/internal/infrastructure/redis"
 	"github.com/NordCoder/Story/internal/infrastructure/wikipedia"
 	mylogger "github.com/NordCoder/Story/internal/logger"
 	"github.com/NordCoder/Story/internal/usecase"
+	"github.com/NordCoder/Story/internal/weighted"
 	config2 "github.com/NordCoder/Story/services/authorization/config"
 	controller2 "github.com/NordCoder/Story/services/authorization/controller"
 	"github.com/NordCoder/Story/services/authorization/db"
@@ -32,7 +40,10 @@ import (
 	"github.com/NordCoder/Story/services/prefetch"
 	"github.com/NordCoder/Story/services/prefetch/category"
 	prefetcherconfig "github.com/NordCoder/Story/services/prefetch/config"
+	"github.com/NordCoder/Story/services/recommendation/bandit"
+	recconfig "github.com/NordCoder/Story/services/recommendation/config"
 	recusecase "github.com/NordCoder/Story/services/recommendation/usecase"
+	recworker "github.com/NordCoder/Story/services/recommendation/worker"
 	"github.com/go-chi/cors"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -54,7 +65,11 @@ func initMetrics() *mylogger.Metrics {
 }
 
 func Run(httpCfg *config.HTTPConfig, logger *zap.Logger) error {
-	ctx := context.Background()
+	// ctx живёт, пока работают серверы: его отмена останавливает фоновые воркеры.
+	// Воркеры из workers дожидаются перед возвратом, чтобы они успели закончить работу с пулом Postgres.
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	var workers sync.WaitGroup
 
 	authCfg, err := config2.NewAuthConfig()
 	if err != nil {
@@ -72,9 +87,11 @@ func Run(httpCfg *config.HTTPConfig, logger *zap.Logger) error {
 
 	r.Use(middleware.Timeout(parseDurationOr(httpCfg.Timeouts.Read, 5*time.Second) + parseDurationOr(httpCfg.Timeouts.Write, 10*time.Second)))
 
+	// Источники CORS перечитываются из http.yaml на лету, остальные параметры — только при старте.
+	origins := newCORSOrigins(httpCfg.CORS.AllowedOrigins)
 	if httpCfg.CORS.Enabled {
 		r.Use(cors.Handler(cors.Options{
-			AllowedOrigins:   httpCfg.CORS.AllowedOrigins,
+			AllowOriginFunc:  origins.Allow,
 			AllowedMethods:   httpCfg.CORS.AllowedMethods,
 			AllowedHeaders:   httpCfg.CORS.AllowedHeaders,
 			AllowCredentials: httpCfg.CORS.AllowCredentials,
@@ -89,12 +106,60 @@ func Run(httpCfg *config.HTTPConfig, logger *zap.Logger) error {
 
 	wiki := wikipedia.NewClient(wikipedia.WithLogger(logger))
 
-	redisClient, err := redis.NewRedisClient()
+	// категории, которые пользователи запросили, но фактов по ним ещё нет
+	advProvider := category.NewStackProvider()
+
+	storageCfg, err := config.NewStorageConfig()
 	if err != nil {
-		logger.Fatal("failed to start redis client", zap.Error(err))
+		logger.Fatal("failed to get storage config", zap.Error(err))
 	}
+	factTTL := parseDurationOr(storageCfg.FactTTL, 5*time.Hour)
+
+	// memory-бэкенд заменяет Redis целиком: факты, блокировки категорий, тренды, состояние
+	// бандита и refresh-токены живут в памяти процесса, и клиент Redis не создаётся вовсе
+	var (
+		factRepo       infrastructure.FactRepository
+		blocklistStore category.BlocklistStore // nil — блокировки только в памяти процесса
+		trendingStore  repository2.TrendingStore
+		sessionRepo    repository.SessionRepository
+		newBanditStore func(ttl time.Duration) repository2.BanditStore
+	)
+	switch storageCfg.Backend {
+	case config.StorageBackendMemory:
+		memoryRepo := memory.NewFactRepository(factTTL, memory.WithCategoryProvider(advProvider))
+		factRepo = memoryRepo
+		go memoryRepo.Run(ctx, parseDurationOr(storageCfg.PurgeInterval, 10*time.Minute), logger)
+
+		trendingStore = repository2.NewMemoryTrendingStore()
+		sessionRepo = repository.NewMemorySessionRepository()
+		newBanditStore = repository2.NewMemoryBanditStore
+	default:
+		redisCfg := config.NewRedisConfig()
+		redisClient, err := redis.NewRedisClient()
+		if err != nil {
+			logger.Fatal("failed to start redis client", zap.Error(err))
+		}
+
+		redisRepo := redis.NewFactRepository(redisClient, factTTL,
+			redis.WithCategoryProvider(advProvider),
+			redis.WithHashTag(redisCfg.HashTag),
+		)
+		factRepo = redisRepo
+
+		if redisCfg.GC.Enabled {
+			sweeper := redis.NewSweeper(redisRepo, parseDurationOr(redisCfg.GC.Interval, 10*time.Minute), redisCfg.GC.BatchSize, logger,
+				redis.WithSweeperMetrics(metrics.Registry))
+			go sweeper.Run(ctx)
+		}
 
-	factRepo := redis.NewFactRepository(redisClient, 5*time.Hour)
+		blocklistStore = redis.NewBlocklistStore(redisClient)
+		trendingStore = repository2.NewTrendingStore(redisClient)
+		sessionRepo = repository.NewRefreshTokenRepository(redisClient, authCfg.RefreshTokenTTL)
+		newBanditStore = func(ttl time.Duration) repository2.BanditStore {
+			return repository2.NewBanditStore(redisClient, ttl)
+		}
+	}
+	logger.Info("fact storage initialized", zap.String("backend", storageCfg.Backend))
 
 	// Лайвнесс: просто проверка, жив ли процесс
 	r.Get(httpCfg.Endpoints.Liveness, controller.LiveHandler)
@@ -103,7 +168,9 @@ func Run(httpCfg *config.HTTPConfig, logger *zap.Logger) error {
 	readinessHandler := controller.NewReadinessHandler()
 
 	// Добавляем сюда все важные зависимости
-	readinessHandler.AddDependency("redis", factRepo)
+	if checker, ok := factRepo.(controller.DependencyChecker); ok {
+		readinessHandler.AddDependency(storageCfg.Backend, checker)
+	}
 	readinessHandler.AddDependency("wikipedia", wiki)
 
 	//todo: add auth to dep in readiness handler
@@ -112,16 +179,48 @@ func Run(httpCfg *config.HTTPConfig, logger *zap.Logger) error {
 	readinessHandler.RegisterRoutes(r, httpCfg.Endpoints.Readiness)
 
 	// provider init
-	wwiiProvider := category.NewDefaultProvider()
-	advProvider := category.NewStackProvider()
-
-	// prefetcher init
 	prefetchConfig, err := prefetcherconfig.NewPrefetcherConfig()
 	if err != nil {
 		logger.Fatal("failed to start prefetcher", zap.Error(err))
 	}
-	prefetcher := prefetch.NewPrefetcher(prefetchConfig, wiki, factRepo, logger, wwiiProvider, advProvider)
-	go func() { prefetcher.Run(ctx) }()
+
+	crawlerConfig, err := prefetcherconfig.NewCrawlerConfig()
+	if err != nil {
+		logger.Fatal("failed to get crawler config", zap.Error(err))
+	}
+	crawlerProvider := category.NewCrawlerProvider(crawlerConfig, wiki, logger)
+	go crawlerProvider.Run(ctx)
+
+	blocklistCfg, err := prefetcherconfig.NewBlocklistConfig()
+	if err != nil {
+		logger.Fatal("failed to get blocklist config", zap.Error(err))
+	}
+	blocklist := category.NewBlocklist()
+	if blocklistStore != nil {
+		blocklist, err = category.NewPersistentBlocklist(ctx, blocklistStore)
+		if err != nil {
+			logger.Fatal("failed to load blocklist", zap.Error(err))
+		}
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			blocklist.Run(ctx, blocklistCfg.SyncInterval, logger)
+		}()
+	}
+
+	trendingProviderCfg, err := prefetcherconfig.NewTrendingProviderConfig()
+	if err != nil {
+		logger.Fatal("failed to get trending provider config", zap.Error(err))
+	}
+
+	providers, err := category.NewRegistry(map[string]category.Provider{
+		"crawler":  crawlerProvider,
+		"stack":    advProvider,
+		"trending": category.NewTrendingProvider(trendingStore, trendingProviderCfg),
+	}, prefetchConfig.ProviderWeights)
+	if err != nil {
+		logger.Fatal("failed to init category providers", zap.Error(err))
+	}
 
 	dbPool, err := pgxpool.New(ctx, authCfg.DB.URL)
 
@@ -134,22 +233,198 @@ func Run(httpCfg *config.HTTPConfig, logger *zap.Logger) error {
 
 	db.SetupPostgres(dbPool, logger)
 
+	prefetchOpts := []prefetch.Option{prefetch.WithBlocklist(blocklist)}
+	var factOpts []usecase.Option
+	if storageCfg.Archive.Enabled {
+		archive := postgres.NewFactArchive(dbPool)
+		prefetchOpts = append(prefetchOpts, prefetch.WithArchive(archive))
+		// поиск идёт по архиву: туда попадает каждый факт, сохранённый префетчером
+		factOpts = append(factOpts, usecase.WithFactArchive(archive), usecase.WithSearch(archive))
+	}
+
+	// prefetcher init
+	prefetcher := prefetch.NewPrefetcher(prefetchConfig, wiki, factRepo, logger, providers, prefetchOpts...)
+	go func() { prefetcher.Run(ctx) }()
+
 	authRepo := repository.NewAuthRepository(dbPool)
-	refreshTokenRepo := repository.NewRefreshTokenRepository(redisClient, authCfg.RefreshTokenTTL)
-	authService := controller2.NewAuthService(authusecase.NewAuthUseCaseImpl(authRepo, refreshTokenRepo, authCfg))
+	authService := controller2.NewAuthService(authusecase.NewAuthUseCaseImpl(authRepo, sessionRepo, authCfg))
+
+	preferencesCfg, err := recconfig.NewPreferencesConfig()
+	if err != nil {
+		logger.Fatal("failed to get preferences config", zap.Error(err))
+	}
+	recRepo := repository2.NewRecRepository(dbPool, repository2.WithHalfLife(preferencesCfg.HalfLife))
+	eventsCfg, err := recconfig.NewEventsConfig()
+	if err != nil {
+		logger.Fatal("failed to get events config", zap.Error(err))
+	}
+	eventRepo := repository2.NewEventRepository(dbPool)
+	eventFlusher := recworker.NewEventFlusher(eventRepo, eventsCfg, logger, recworker.WithEventFlusherMetrics(metrics.Registry))
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		eventFlusher.Run(ctx)
+	}()
 
-	recRepo := repository2.NewRecRepository(dbPool)
-	recService := controller3.NewRecService(recusecase.NewRecUseCase(recRepo))
+	coldStartCfg, err := recconfig.NewColdStartConfig()
+	if err != nil {
+		logger.Fatal("failed to get cold start config", zap.Error(err))
+	}
 
-	ctrl := controller.New(usecase.NewFactUseCase(factRepo, recService))
+	interestsCfg, err := recconfig.NewInterestsConfig()
+	if err != nil {
+		logger.Fatal("failed to get interests config", zap.Error(err))
+	}
+
+	trendingCfg, err := recconfig.NewTrendingConfig()
+	if err != nil {
+		logger.Fatal("failed to get trending config", zap.Error(err))
+	}
+	if _, ok := trendingCfg.Window(trendingProviderCfg.Window); !ok {
+		logger.Fatal("trending provider window is not among trending windows", zap.String("window", trendingProviderCfg.Window))
+	}
+	trendingAggregator := recworker.NewTrendingAggregator(repository2.NewTrendingRepository(dbPool), trendingStore, trendingCfg, logger)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		trendingAggregator.Run(ctx)
+	}()
+
+	collaborativeCfg, err := recconfig.NewCollaborativeConfig()
+	if err != nil {
+		logger.Fatal("failed to get collaborative config", zap.Error(err))
+	}
+	similarityRepo := repository2.NewSimilarityRepository(dbPool, repository2.WithHalfLife(preferencesCfg.HalfLife))
+	similarityBuilder := recworker.NewSimilarityBuilder(similarityRepo, collaborativeCfg, logger)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		similarityBuilder.Run(ctx)
+	}()
+
+	banditCfg, err := recconfig.NewBanditConfig()
+	if err != nil {
+		logger.Fatal("failed to get bandit config", zap.Error(err))
+	}
+
+	propagationCfg, err := recconfig.NewPropagationConfig()
+	if err != nil {
+		logger.Fatal("failed to get propagation config", zap.Error(err))
+	}
+	interestCatalog := recusecase.NewInterestCatalog(interestsCfg, wiki)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		interestCatalog.Run(ctx)
+	}()
+
+	propagationWorker := recworker.NewPropagationWorker(recRepo, wiki, propagationCfg, logger)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		propagationWorker.Start(ctx)
+	}()
+
+	recOpts := []recusecase.Option{
+		recusecase.WithEvents(eventFlusher, eventRepo, eventsCfg.MaxRequestEvents, eventsCfg.SignalWindow),
+		recusecase.WithPropagator(propagationWorker),
+		recusecase.WithColdStart(coldStartCfg),
+		recusecase.WithTrending(trendingStore, trendingCfg),
+		recusecase.WithSimilar(similarityRepo, collaborativeCfg.Share),
+		recusecase.WithInterests(interestCatalog, interestsCfg.InitialWeight),
+	}
+	if banditCfg.Enabled {
+		recOpts = append(recOpts, recusecase.WithBandit(newBanditStore(banditCfg.StateTTL), bandit.NewThompson(), banditCfg))
+	}
+	recService := controller3.NewRecService(recusecase.NewRecUseCase(recRepo, repository2.NewReactionRepository(dbPool), factRepo, recOpts...))
+
+	feedCfg, err := config.NewFeedConfig()
+	if err != nil {
+		logger.Fatal("failed to get feed config", zap.Error(err))
+	}
+	feedStrategies, err := weighted.NewChooser(feedCfg.StrategyWeights)
+	if err != nil {
+		logger.Fatal("failed to init feed strategies", zap.Error(err))
+	}
+
+	experimentsCfg, err := config.LoadExperimentsConfig()
+	if err != nil {
+		logger.Fatal("failed to get experiments config", zap.Error(err))
+	}
+	assigner, err := experiment.NewAssigner(experimentsCfg)
+	if err != nil {
+		logger.Fatal("failed to init experiments", zap.Error(err))
+	}
+	serveLog := postgres.NewServeLog(dbPool)
+	factOpts = append(factOpts,
+		usecase.WithServeLog(serveLog),
+		usecase.WithRelated(wiki, prefetcher, feedCfg.Related),
+	)
+
+	ctrl := controller.New(usecase.NewFactUseCase(factRepo, recService, feedStrategies, factOpts...))
+
+	watcher := config.NewWatcher(logger, config.WithReloadMetrics(metrics.Registry))
+	watcher.OnChange(config.HTTPConfigPath, func() error {
+		cfg, err := config.LoadHTTPConfig()
+		if err != nil {
+			return err
+		}
+		origins.Set(cfg.CORS.AllowedOrigins)
+		return nil
+	})
+	watcher.OnChange(config.LoggerConfigPath, func() error {
+		cfg, err := config.LoadLoggerConfig()
+		if err != nil {
+			return err
+		}
+		return mylogger.SetLevel(cfg.Level)
+	})
+	watcher.OnChange(prefetcherconfig.PrefetcherConfigPath, func() error {
+		cfg, err := prefetcherconfig.NewPrefetcherConfig()
+		if err != nil {
+			return err
+		}
+		// конфиг уже провалидирован, поэтому отказать могут только веса — и тогда ничего не применится
+		if err := providers.SetWeights(cfg.ProviderWeights); err != nil {
+			return err
+		}
+		return prefetcher.SetConfig(cfg)
+	})
+	watcher.OnChange(config.FeedConfigPath, func() error {
+		cfg, err := config.NewFeedConfig()
+		if err != nil {
+			return err
+		}
+		return feedStrategies.Set(cfg.StrategyWeights)
+	})
+	watcher.OnChange(config.ExperimentsConfigPath, func() error {
+		cfg, err := config.LoadExperimentsConfig()
+		if err != nil {
+			return err
+		}
+		return assigner.Set(cfg)
+	})
+	go func() {
+		if err := watcher.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			logger.Error("config watcher stopped", zap.Error(err))
+		}
+	}()
+
+	adminService := admincontroller.NewAdminService(adminusecase.NewAdminUseCase(providers, blocklist, prefetcher,
+		adminusecase.WithExperiments(assigner, serveLog),
+	), authCfg.Admins)
 
 	grpcSrv := grpc.NewServer(
-		grpc.UnaryInterceptor(auth.UnaryInterceptor(authCfg.JWTSecret)),
+		grpc.ChainUnaryInterceptor(
+			auth.UnaryInterceptor(authCfg.JWTSecret),
+			experiment.UnaryInterceptor(assigner),
+		),
 	)
 	//todo: think about this thing
 	storypb.RegisterStoryServer(grpcSrv, ctrl)
 	storypb.RegisterAuthServiceServer(grpcSrv, authService)
 	storypb.RegisterRecommendationServer(grpcSrv, recService)
+	storypb.RegisterAdminServiceServer(grpcSrv, adminService)
 	// server start
 	lis, err := net.Listen("tcp", ":"+httpCfg.GrpcPort)
 	if err != nil {
@@ -184,6 +459,11 @@ func Run(httpCfg *config.HTTPConfig, logger *zap.Logger) error {
 		return err
 	}
 
+	if err := storypb.RegisterAdminServiceHandlerFromEndpoint(ctx, gw, httpCfg.GrpcHost+":"+httpCfg.GrpcPort, []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}); err != nil {
+		logger.Error("grpc-gateway admin registration failed", zap.Error(err))
+		return err
+	}
+
 	// Public routes: auth
 	r.Mount("/v1/auth", gw)
 
@@ -193,6 +473,9 @@ func Run(httpCfg *config.HTTPConfig, logger *zap.Logger) error {
 	// Protected Recommendation routes
 	r.With(auth.HTTPMiddleware(authCfg.JWTSecret)).Mount("/v1/recommendations", gw)
 
+	// Protected Admin routes
+	r.With(auth.HTTPMiddleware(authCfg.JWTSecret)).Mount("/v1/admin", gw)
+
 	addr := fmt.Sprintf("%s:%s", httpCfg.Host, httpCfg.Port)
 	srv := &http.Server{
 		Addr:         addr,
@@ -224,7 +507,7 @@ func Run(httpCfg *config.HTTPConfig, logger *zap.Logger) error {
 	<-quit
 	logger.Info("Shutdown signal received")
 
-	ctxShut, cancel := context.WithTimeout(ctx, parseDurationOr(httpCfg.Timeouts.ShutdownGracePeriod, 15*time.Second))
+	ctxShut, cancel := context.WithTimeout(context.Background(), parseDurationOr(httpCfg.Timeouts.ShutdownGracePeriod, 15*time.Second))
 	defer cancel()
 
 	if err := srv.Shutdown(ctxShut); err != nil {
@@ -232,6 +515,9 @@ func Run(httpCfg *config.HTTPConfig, logger *zap.Logger) error {
 	}
 	grpcSrv.GracefulStop()
 
+	stop()
+	workers.Wait()
+
 	return nil
 }
 
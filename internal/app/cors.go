@@ -0,0 +1,35 @@
+package app
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// corsOrigins — список разрешённых CORS-источников, который можно заменить на лету.
+type corsOrigins struct {
+	origins atomic.Pointer[map[string]struct{}]
+}
+
+func newCORSOrigins(origins []string) *corsOrigins {
+	c := &corsOrigins{}
+	c.Set(origins)
+	return c
+}
+
+func (c *corsOrigins) Set(origins []string) {
+	set := make(map[string]struct{}, len(origins))
+	for _, o := range origins {
+		set[o] = struct{}{}
+	}
+	c.origins.Store(&set)
+}
+
+// Allow подходит как cors.Options.AllowOriginFunc.
+func (c *corsOrigins) Allow(_ *http.Request, origin string) bool {
+	set := *c.origins.Load()
+	if _, ok := set["*"]; ok {
+		return true
+	}
+	_, ok := set[origin]
+	return ok
+}
@@ -3,6 +3,7 @@ package controller
 import (
 	"context"
 	storypb "github.com/NordCoder/Story/generated/api/proto/v1"
+	"github.com/NordCoder/Story/internal/entity"
 	"github.com/NordCoder/Story/internal/logger"
 	"github.com/NordCoder/Story/internal/usecase"
 	"github.com/golang/protobuf/ptypes/empty"
@@ -19,12 +20,37 @@ func (i *implementation) GetFact(ctx context.Context, empty *empty.Empty) (*stor
 	}
 
 	return &storypb.GetFactResponse{
-		Fact: &storypb.Fact{
-			Title:    fact.Fact.Title,
-			Category: string(fact.Fact.Category),
-			Summary:  fact.Fact.Summary,
-			WikiUrl:  fact.Fact.SourceURL,
-			ImgUrl:   fact.Fact.ImageURL,
-		},
+		Fact:   factToProto(fact.Fact),
+		Reason: reasonToProto(fact.Reason),
 	}, nil
 }
+
+func factToProto(f entity.Fact) *storypb.Fact {
+	return &storypb.Fact{
+		Title:    f.Title,
+		Category: string(f.Category),
+		Summary:  f.Summary,
+		WikiUrl:  f.SourceURL,
+		ImgUrl:   f.ImageURL,
+		Id:       string(f.ID),
+	}
+}
+
+var reasonPaths = map[usecase.ReasonPath]storypb.ReasonPath{
+	usecase.ReasonPersonal:   storypb.ReasonPath_REASON_PATH_PERSONAL,
+	usecase.ReasonTrending:   storypb.ReasonPath_REASON_PATH_TRENDING,
+	usecase.ReasonOnboarding: storypb.ReasonPath_REASON_PATH_ONBOARDING,
+	usecase.ReasonRandom:     storypb.ReasonPath_REASON_PATH_RANDOM,
+	usecase.ReasonFallback:   storypb.ReasonPath_REASON_PATH_FALLBACK,
+}
+
+func reasonToProto(r usecase.Reason) *storypb.FactReason {
+	return &storypb.FactReason{
+		Path:        reasonPaths[r.Path],
+		Category:    string(r.Category),
+		Source:      string(r.Source),
+		ViaCategory: string(r.Via),
+		Score:       r.Score,
+		Explored:    r.Explored,
+	}
+}
@@ -0,0 +1,47 @@
+package controller
+
+import (
+	"context"
+
+	storypb "github.com/NordCoder/Story/generated/api/proto/v1"
+	"github.com/NordCoder/Story/internal/entity"
+	"github.com/NordCoder/Story/internal/logger"
+	"github.com/NordCoder/Story/internal/usecase"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (i *implementation) GetRelatedFacts(ctx context.Context, req *storypb.GetRelatedFactsRequest) (*storypb.GetRelatedFactsResponse, error) {
+	if err := req.ValidateAll(); err != nil {
+		logger.LoggerFromContext(ctx).Info("GetRelatedFacts validate fail", zap.Error(err))
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	out, err := i.factUseCase.GetRelatedFacts(ctx, usecase.GetRelatedFactsInput{
+		FactID: entity.FactID(req.GetFactId()),
+		Limit:  int(req.GetLimit()),
+	})
+	if err != nil {
+		logger.LoggerFromContext(ctx).Error("failed to get related facts", zap.Error(err))
+		return nil, GRPCError(err)
+	}
+
+	resp := &storypb.GetRelatedFactsResponse{
+		Facts:   make([]*storypb.RelatedFact, 0, len(out.Facts)),
+		Partial: out.Partial,
+	}
+	for _, f := range out.Facts {
+		resp.Facts = append(resp.Facts, &storypb.RelatedFact{
+			Fact:     factToProto(f.Fact),
+			Relation: relations[f.Relation],
+		})
+	}
+	return resp, nil
+}
+
+var relations = map[usecase.Relation]storypb.Relation{
+	usecase.RelationSameCategory:    storypb.Relation_RELATION_SAME_CATEGORY,
+	usecase.RelationSiblingCategory: storypb.Relation_RELATION_SIBLING_CATEGORY,
+	usecase.RelationLinked:          storypb.Relation_RELATION_LINKED,
+}
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"context"
+
+	storypb "github.com/NordCoder/Story/generated/api/proto/v1"
+	"github.com/NordCoder/Story/internal/entity"
+	"github.com/NordCoder/Story/internal/logger"
+	"github.com/NordCoder/Story/internal/usecase"
+	auth "github.com/NordCoder/Story/services/authorization/transport/http"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// defaultSearchPageSize — размер страницы SearchFacts, если клиент его не указал.
+const defaultSearchPageSize = 20
+
+func (i *implementation) SearchFacts(ctx context.Context, req *storypb.SearchFactsRequest) (*storypb.SearchFactsResponse, error) {
+	if err := req.ValidateAll(); err != nil {
+		logger.LoggerFromContext(ctx).Info("SearchFacts validate fail", zap.Error(err))
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	offset, err := decodePageToken(req.GetPageToken())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+	}
+	pageSize := int(req.GetPageSize())
+	if pageSize == 0 {
+		pageSize = defaultSearchPageSize
+	}
+
+	input := usecase.SearchFactsInput{
+		Query:    req.GetQuery(),
+		Category: entity.Category(req.GetCategory()),
+		Lang:     req.GetLang(),
+		Limit:    pageSize,
+		Offset:   offset,
+	}
+	if req.GetOnlySeen() {
+		id, err := auth.UserIDFromCtx(ctx)
+		if err != nil {
+			logger.LoggerFromContext(ctx).Info("Failed to get id from context", zap.Error(err))
+			return nil, status.Error(codes.Unauthenticated, "user not authenticated")
+		}
+		input.SeenBy = string(id)
+	}
+
+	out, err := i.factUseCase.SearchFacts(ctx, input)
+	if err != nil {
+		return nil, GRPCError(err)
+	}
+
+	resp := &storypb.SearchFactsResponse{
+		Hits:       make([]*storypb.FactSearchHit, 0, len(out.Hits)),
+		TotalCount: int32(out.Total),
+	}
+	for _, h := range out.Hits {
+		resp.Hits = append(resp.Hits, &storypb.FactSearchHit{
+			Fact:    factToProto(h.Fact),
+			Snippet: h.Snippet,
+			Rank:    h.Rank,
+		})
+	}
+	if next := offset + len(out.Hits); len(out.Hits) > 0 && next < out.Total {
+		resp.NextPageToken = encodePageToken(next)
+	}
+	return resp, nil
+}
@@ -1,9 +1,12 @@
 package controller
 
 import (
+	"encoding/base64"
 	"errors"
+	"strconv"
 
 	"github.com/NordCoder/Story/internal/entity"
+	"github.com/NordCoder/Story/internal/usecase"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -13,8 +16,34 @@ func GRPCError(err error) error {
 	switch {
 	case errors.Is(err, entity.ErrFactNotFound):
 		return status.Errorf(codes.NotFound, "resource not found")
+	case errors.Is(err, usecase.ErrRelatedDisabled):
+		return status.Errorf(codes.Unavailable, "related facts are disabled")
+	case errors.Is(err, usecase.ErrSearchDisabled):
+		return status.Errorf(codes.Unavailable, "fact search is disabled")
+	case errors.Is(err, usecase.ErrEmptyQuery):
+		return status.Errorf(codes.InvalidArgument, "search query is empty")
 	// можно добавлять новые случаи здесь в будущем
 	default:
 		return status.Errorf(codes.Internal, "internal server error")
 	}
 }
+
+// encodePageToken прячет смещение в непрозрачный токен, чтобы клиенты не строили его сами.
+func encodePageToken(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func decodePageToken(token string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, err
+	}
+	offset, err := strconv.Atoi(string(raw))
+	if err != nil || offset < 0 {
+		return 0, errors.New("invalid page token")
+	}
+	return offset, nil
+}
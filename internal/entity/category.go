@@ -2,7 +2,10 @@ package entity
 
 import "errors"
 
-var ErrCategoryNotFound = errors.New("category not found")
+var (
+	ErrCategoryNotFound = errors.New("category not found")
+	ErrCategoryBlocked  = errors.New("category is blocklisted")
+)
 
 type Category string
 
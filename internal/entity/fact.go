@@ -29,3 +29,6 @@ type Fact struct {
 
 // ErrFactNotFound возвращается, когда факт с данным ID не найден.
 var ErrFactNotFound = errors.New("fact not found")
+
+// ErrFactExists возвращается Save, когда факт с данным ID уже сохранён и ещё не истёк.
+var ErrFactExists = errors.New("fact already exists")
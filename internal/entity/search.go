@@ -0,0 +1,18 @@
+package entity
+
+// FactQuery — полнотекстовый поиск по фактам.
+type FactQuery struct {
+	Text     string   // запрос в синтаксисе веб-поиска: слова, "фразы", -исключения, or
+	Category Category // пусто — любая категория
+	Lang     string   // пусто — любой язык
+	SeenBy   string   // ID пользователя: искать только среди показанных ему фактов; пусто — среди всех
+	Limit    int
+	Offset   int
+}
+
+// FactHit — найденный факт.
+type FactHit struct {
+	Fact    Fact
+	Snippet string  // фрагмент текста с найденными словами, выделенными «»
+	Rank    float64 // релевантность; сравнима только внутри одного запроса
+}
@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+// ServedFact — запись о показе факта пользователю.
+type ServedFact struct {
+	UserID   string
+	FactID   FactID
+	Category Category
+	Reason   string            // путь выдачи, см. usecase.ReasonPath
+	Variants map[string]string // эксперимент → вариант пользователя на момент показа
+}
+
+// VariantStats — вовлечённость пользователей одного варианта эксперимента.
+type VariantStats struct {
+	Variant  string
+	Users    int64
+	Serves   int64
+	Likes    int64 // показы, на которые первой реакцией был лайк
+	Dislikes int64
+	Skips    int64
+	Engaged  int64         // показы, после которых открыли источник, развернули текст или поделились
+	AvgDwell time.Duration // среднее время на карточке по реакциям
+}
@@ -0,0 +1,128 @@
+package experiment
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+
+	"github.com/NordCoder/Story/config"
+	"github.com/NordCoder/Story/internal/weighted"
+)
+
+// Assignment — вариант эксперимента, в который попал пользователь.
+type Assignment struct {
+	Experiment string
+	Variant    string
+	// strategies — веса стратегий выдачи варианта; nil — вариант их не переопределяет.
+	strategies *weighted.Chooser
+}
+
+type variant struct {
+	name       string
+	upper      float64 // верхняя граница доли варианта на отрезке [0, 1)
+	strategies *weighted.Chooser
+}
+
+type compiled struct {
+	name     string
+	variants []variant
+}
+
+// Assigner детерминированно распределяет пользователей по вариантам включённых экспериментов.
+// Конфиг можно заменить на лету через Set.
+type Assigner struct {
+	mu          sync.RWMutex
+	experiments []compiled
+	known       map[string]struct{}
+}
+
+func NewAssigner(cfg *config.ExperimentsConfig) (*Assigner, error) {
+	a := &Assigner{}
+	if err := a.Set(cfg); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Set атомарно заменяет эксперименты; некорректный конфиг отклоняется целиком.
+func (a *Assigner) Set(cfg *config.ExperimentsConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	experiments := make([]compiled, 0, len(cfg.Experiments))
+	known := make(map[string]struct{}, len(cfg.Experiments))
+	for _, e := range cfg.Experiments {
+		known[e.Name] = struct{}{}
+		if !e.Enabled {
+			continue
+		}
+
+		var total float64
+		for _, v := range e.Variants {
+			total += v.Traffic
+		}
+		c := compiled{name: e.Name, variants: make([]variant, 0, len(e.Variants))}
+		var cum float64
+		for _, v := range e.Variants {
+			cum += v.Traffic
+			compiledVariant := variant{name: v.Name, upper: cum / total}
+			if len(v.StrategyWeights) > 0 {
+				chooser, err := weighted.NewChooser(v.StrategyWeights)
+				if err != nil {
+					return fmt.Errorf("experiments: %q/%q: %w", e.Name, v.Name, err)
+				}
+				compiledVariant.strategies = chooser
+			}
+			c.variants = append(c.variants, compiledVariant)
+		}
+		experiments = append(experiments, c)
+	}
+
+	a.mu.Lock()
+	a.experiments = experiments
+	a.known = known
+	a.mu.Unlock()
+	return nil
+}
+
+// Known сообщает, описан ли эксперимент в конфиге (включённым или нет).
+func (a *Assigner) Known(name string) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	_, ok := a.known[name]
+	return ok
+}
+
+// Assign возвращает варианты пользователя во всех включённых экспериментах.
+func (a *Assigner) Assign(userID string) []Assignment {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	assignments := make([]Assignment, 0, len(a.experiments))
+	for _, e := range a.experiments {
+		v := e.pick(bucket(e.name, userID))
+		assignments = append(assignments, Assignment{Experiment: e.name, Variant: v.name, strategies: v.strategies})
+	}
+	return assignments
+}
+
+func (e compiled) pick(point float64) variant {
+	for _, v := range e.variants {
+		if point < v.upper {
+			return v
+		}
+	}
+	// point < 1 всегда, но последняя граница может оказаться чуть меньше 1 из-за округления
+	return e.variants[len(e.variants)-1]
+}
+
+// bucket отображает пару (эксперимент, пользователь) в точку на [0, 1). Имя эксперимента
+// входит в хэш, чтобы варианты разных экспериментов не коррелировали между собой.
+func bucket(experiment, userID string) float64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(experiment))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(userID))
+	return float64(h.Sum64()>>11) / float64(uint64(1)<<53)
+}
@@ -0,0 +1,44 @@
+package experiment
+
+import (
+	"context"
+
+	"github.com/NordCoder/Story/internal/weighted"
+)
+
+type ctxAssignmentsKey struct{}
+
+// WithAssignments кладёт варианты пользователя в контекст запроса.
+func WithAssignments(ctx context.Context, assignments []Assignment) context.Context {
+	return context.WithValue(ctx, ctxAssignmentsKey{}, assignments)
+}
+
+// FromContext возвращает варианты пользователя из контекста или nil.
+func FromContext(ctx context.Context) []Assignment {
+	assignments, _ := ctx.Value(ctxAssignmentsKey{}).([]Assignment)
+	return assignments
+}
+
+// Variants возвращает варианты из контекста в виде эксперимент → вариант.
+func Variants(ctx context.Context) map[string]string {
+	assignments := FromContext(ctx)
+	if len(assignments) == 0 {
+		return nil
+	}
+	variants := make(map[string]string, len(assignments))
+	for _, a := range assignments {
+		variants[a.Experiment] = a.Variant
+	}
+	return variants
+}
+
+// StrategyChooser возвращает веса стратегий выдачи из варианта пользователя или nil,
+// если ни один вариант их не переопределяет.
+func StrategyChooser(ctx context.Context) *weighted.Chooser {
+	for _, a := range FromContext(ctx) {
+		if a.strategies != nil {
+			return a.strategies
+		}
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package experiment
+
+import (
+	"context"
+
+	auth "github.com/NordCoder/Story/services/authorization/transport/http"
+	"google.golang.org/grpc"
+)
+
+// UnaryInterceptor назначает варианты аутентифицированному пользователю и кладёт их в контекст.
+// Должен стоять после auth.UnaryInterceptor; запросы без пользователя проходят как есть.
+func UnaryInterceptor(a *Assigner) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if id, err := auth.UserIDFromCtx(ctx); err == nil {
+			ctx = WithAssignments(ctx, a.Assign(string(id)))
+		}
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,237 @@
+// Package facttest — общий контракт infrastructure.FactRepository. Каждая реализация
+// прогоняет его в своих тестах, чтобы Redis и память вели себя одинаково.
+package facttest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/NordCoder/Story/internal/entity"
+	"github.com/NordCoder/Story/internal/infrastructure"
+)
+
+// Factory создаёт пустой репозиторий с заданным ttl и функцию, продвигающую его время
+// на d: фейковые часы для памяти, ожидание для Redis.
+type Factory func(t *testing.T, ttl time.Duration) (repo infrastructure.FactRepository, advance func(d time.Duration))
+
+// TTL — срок жизни фактов в тестах на истечение; для Redis он же время ожидания.
+const TTL = 200 * time.Millisecond
+
+// Run прогоняет контракт против реализации, которую создаёт newRepo.
+func Run(t *testing.T, newRepo Factory) {
+	t.Run("SaveThenGetByID", func(t *testing.T) { testSaveThenGetByID(t, newRepo) })
+	t.Run("GetByIDMissing", func(t *testing.T) { testGetByIDMissing(t, newRepo) })
+	t.Run("SaveExisting", func(t *testing.T) { testSaveExisting(t, newRepo) })
+	t.Run("SaveUnlisted", func(t *testing.T) { testSaveUnlisted(t, newRepo) })
+	t.Run("GetByIDsKeepsOrder", func(t *testing.T) { testGetByIDsKeepsOrder(t, newRepo) })
+	t.Run("PopRandomIsFIFO", func(t *testing.T) { testPopRandomIsFIFO(t, newRepo) })
+	t.Run("GetByCategory", func(t *testing.T) { testGetByCategory(t, newRepo) })
+	t.Run("Expiry", func(t *testing.T) { testExpiry(t, newRepo) })
+}
+
+// NewFact возвращает факт категории category с уникальным ID.
+func NewFact(category entity.Category, i int) *entity.Fact {
+	return &entity.Fact{
+		ID:        entity.NewFactID(),
+		Category:  category,
+		Title:     fmt.Sprintf("%s %d", category, i),
+		Summary:   "summary",
+		SourceURL: fmt.Sprintf("https://ru.wikipedia.org/wiki/%s_%d", category, i),
+		Lang:      "ru",
+		FetchedAt: time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+func save(t *testing.T, repo infrastructure.FactRepository, facts ...*entity.Fact) {
+	t.Helper()
+	for _, f := range facts {
+		if err := repo.Save(context.Background(), f); err != nil {
+			t.Fatalf("Save(%s): %v", f.ID, err)
+		}
+	}
+}
+
+func testSaveThenGetByID(t *testing.T, newRepo Factory) {
+	repo, _ := newRepo(t, time.Hour)
+	want := NewFact("history", 0)
+	save(t, repo, want)
+
+	got, err := repo.GetByID(context.Background(), want.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != want.ID || got.Category != want.Category || got.Title != want.Title ||
+		got.SourceURL != want.SourceURL || !got.FetchedAt.Equal(want.FetchedAt) {
+		t.Errorf("GetByID() = %+v, want %+v", got, want)
+	}
+}
+
+func testGetByIDMissing(t *testing.T, newRepo Factory) {
+	repo, _ := newRepo(t, time.Hour)
+	if _, err := repo.GetByID(context.Background(), entity.NewFactID()); !errors.Is(err, entity.ErrFactNotFound) {
+		t.Errorf("GetByID() error = %v, want ErrFactNotFound", err)
+	}
+}
+
+func testSaveExisting(t *testing.T, newRepo Factory) {
+	ctx := context.Background()
+	repo, _ := newRepo(t, time.Hour)
+	first := NewFact("history", 0)
+	save(t, repo, first)
+
+	second := *first
+	second.Title = "overwritten"
+	if err := repo.Save(ctx, &second); !errors.Is(err, entity.ErrFactExists) {
+		t.Fatalf("Save() of an existing ID error = %v, want ErrFactExists", err)
+	}
+
+	got, err := repo.GetByID(ctx, first.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != first.Title {
+		t.Errorf("Title = %q, want the first saved %q", got.Title, first.Title)
+	}
+	if n, _ := repo.CountFacts(ctx); n != 1 {
+		t.Errorf("CountFacts() = %d, want 1", n)
+	}
+
+	// снятый с очереди факт повторным Save в неё не возвращается
+	if _, err := repo.PopRandom(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Save(ctx, first); !errors.Is(err, entity.ErrFactExists) {
+		t.Fatalf("Save() after pop error = %v, want ErrFactExists", err)
+	}
+	if n, _ := repo.CountFacts(ctx); n != 0 {
+		t.Errorf("CountFacts() after re-save = %d, want 0", n)
+	}
+}
+
+func testSaveUnlisted(t *testing.T, newRepo Factory) {
+	ctx := context.Background()
+	repo, _ := newRepo(t, time.Hour)
+	f := NewFact("unlisted", 0)
+	if err := repo.SaveUnlisted(ctx, f); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SaveUnlisted(ctx, f); !errors.Is(err, entity.ErrFactExists) {
+		t.Errorf("second SaveUnlisted() error = %v, want ErrFactExists", err)
+	}
+
+	got, err := repo.GetByID(ctx, f.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != f.Title {
+		t.Errorf("Title = %q, want %q", got.Title, f.Title)
+	}
+	if n, _ := repo.CountFacts(ctx); n != 0 {
+		t.Errorf("CountFacts() = %d, want 0: unlisted fact was queued", n)
+	}
+	if _, err := repo.GetByCategory(ctx, "unlisted", 10); !errors.Is(err, entity.ErrCategoryNotFound) {
+		t.Errorf("GetByCategory() error = %v, want ErrCategoryNotFound: unlisted fact was indexed", err)
+	}
+}
+
+func testGetByIDsKeepsOrder(t *testing.T, newRepo Factory) {
+	repo, _ := newRepo(t, time.Hour)
+	a, b := NewFact("history", 0), NewFact("history", 1)
+	save(t, repo, a, b)
+
+	facts, err := repo.GetByIDs(context.Background(), []entity.FactID{b.ID, entity.NewFactID(), a.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(facts) != 2 || facts[0].ID != b.ID || facts[1].ID != a.ID {
+		t.Errorf("GetByIDs() = %v, want [%s %s]", facts, b.ID, a.ID)
+	}
+}
+
+func testPopRandomIsFIFO(t *testing.T, newRepo Factory) {
+	ctx := context.Background()
+	repo, _ := newRepo(t, time.Hour)
+	facts := []*entity.Fact{NewFact("history", 0), NewFact("art", 1), NewFact("history", 2)}
+	save(t, repo, facts...)
+
+	for _, want := range facts {
+		got, err := repo.PopRandom(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got == nil || got.ID != want.ID {
+			t.Fatalf("PopRandom() = %v, want %s", got, want.ID)
+		}
+	}
+	if got, err := repo.PopRandom(ctx); got != nil || err != nil {
+		t.Errorf("PopRandom() on empty queue = %v, %v; want nil, nil", got, err)
+	}
+
+	// снятый с очереди факт остаётся доступным по ID
+	if _, err := repo.GetByID(ctx, facts[0].ID); err != nil {
+		t.Errorf("GetByID() after pop: %v", err)
+	}
+}
+
+func testGetByCategory(t *testing.T, newRepo Factory) {
+	ctx := context.Background()
+	repo, _ := newRepo(t, time.Hour)
+	for i := 0; i < 5; i++ {
+		save(t, repo, NewFact("history", i))
+	}
+	save(t, repo, NewFact("art", 0))
+
+	if _, err := repo.GetByCategory(ctx, "unknown", 3); !errors.Is(err, entity.ErrCategoryNotFound) {
+		t.Errorf("GetByCategory(unknown) error = %v, want ErrCategoryNotFound", err)
+	}
+
+	facts, err := repo.GetByCategory(ctx, "history", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(facts) != 3 {
+		t.Errorf("GetByCategory() returned %d facts, want 3", len(facts))
+	}
+	seen := make(map[entity.FactID]struct{})
+	for _, f := range facts {
+		if f.Category != "history" {
+			t.Errorf("fact %s has category %q", f.ID, f.Category)
+		}
+		if _, ok := seen[f.ID]; ok {
+			t.Errorf("fact %s returned twice", f.ID)
+		}
+		seen[f.ID] = struct{}{}
+	}
+}
+
+func testExpiry(t *testing.T, newRepo Factory) {
+	ctx := context.Background()
+	repo, advance := newRepo(t, TTL)
+	f := NewFact("history", 0)
+	save(t, repo, f)
+	advance(2 * TTL)
+
+	if _, err := repo.GetByID(ctx, f.ID); !errors.Is(err, entity.ErrFactNotFound) {
+		t.Errorf("GetByID() after expiry error = %v, want ErrFactNotFound", err)
+	}
+	if got, err := repo.PopRandom(ctx); got != nil || err != nil {
+		t.Errorf("PopRandom() after expiry = %v, %v; want nil, nil", got, err)
+	}
+
+	// первая выборка натыкается на истёкший ID и вычищает его из индекса категории
+	if facts, err := repo.GetByCategory(ctx, "history", 10); len(facts) != 0 || (err != nil && !errors.Is(err, entity.ErrCategoryNotFound)) {
+		t.Errorf("GetByCategory() after expiry = %v, %v; want no facts", facts, err)
+	}
+	if _, err := repo.GetByCategory(ctx, "history", 10); !errors.Is(err, entity.ErrCategoryNotFound) {
+		t.Errorf("GetByCategory() after cleanup error = %v, want ErrCategoryNotFound", err)
+	}
+
+	// истёкший ID не мешает сохранить факт заново
+	save(t, repo, f)
+	if _, err := repo.GetByID(ctx, f.ID); err != nil {
+		t.Errorf("GetByID() after re-save: %v", err)
+	}
+}
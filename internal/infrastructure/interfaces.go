@@ -2,20 +2,56 @@ package infrastructure
 
 import (
 	"context"
+	"time"
 
 	"github.com/NordCoder/Story/internal/entity"
 )
 
 // FactRepository описывает хранилище фактов.
 //
-//	– Save сохраняет новый факт; перезапись по тому же ID не происходит.
+//	– Save сохраняет новый факт; для живого факта с тем же ID возвращает ErrFactExists и ничего не меняет.
+//	– SaveUnlisted сохраняет факт так же, но не ставит его в очередь и не добавляет в индекс
+//	  категории: такой факт достаётся только по ID.
 //	– GetByID возвращает факт по ID или ErrFactNotFound.
+//	– GetByIDs за один запрос возвращает найденные факты в порядке ids, пропуская отсутствующие.
 //	– PopRandom извлекает и удаляет один случайный ID из очереди, возвращая весь факт.
+//	– CountFacts возвращает число фактов в очереди.
 type FactRepository interface {
 	Save(ctx context.Context, f *entity.Fact) error
+	SaveUnlisted(ctx context.Context, f *entity.Fact) error
 	GetByID(ctx context.Context, id entity.FactID) (*entity.Fact, error)
+	GetByIDs(ctx context.Context, ids []entity.FactID) ([]*entity.Fact, error)
 	PopRandom(ctx context.Context) (*entity.Fact, error)
 	GetByCategory(ctx context.Context, category entity.Category, count int) ([]*entity.Fact, error)
+	CountFacts(ctx context.Context) (int64, error)
+}
+
+// FactArchive — долговременное хранилище всех загруженных фактов, над которым FactRepository служит кэшем.
+//
+//	– Archive сохраняет факт и возвращает его ID в архиве; для уже заархивированной статьи
+//	  (тот же source_url) запись не меняется и возвращается её прежний ID.
+//	– MarkServed учитывает показ факта пользователю.
+//	– GetByCategory возвращает до limit фактов категории, сначала реже показанные.
+type FactArchive interface {
+	Archive(ctx context.Context, f *entity.Fact) (entity.FactID, error)
+	MarkServed(ctx context.Context, id entity.FactID) error
+	GetByCategory(ctx context.Context, category entity.Category, limit int) ([]*entity.Fact, error)
+}
+
+// FactSearcher — полнотекстовый поиск по фактам.
+//
+//	– Search возвращает страницу найденных фактов, начиная с самых релевантных, и общее число совпадений.
+type FactSearcher interface {
+	Search(ctx context.Context, q entity.FactQuery) ([]entity.FactHit, int, error)
+}
+
+// ServeLog — журнал показов фактов для анализа экспериментов.
+//
+//	– Record сохраняет показ вместе с вариантами пользователя.
+//	– Report сравнивает вовлечённость вариантов эксперимента по показам начиная с since.
+type ServeLog interface {
+	Record(ctx context.Context, s entity.ServedFact) error
+	Report(ctx context.Context, experiment string, since time.Time) ([]entity.VariantStats, error)
 }
 
 type FetchClient interface {
@@ -0,0 +1,277 @@
+package memory
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/NordCoder/Story/internal/entity"
+	"github.com/NordCoder/Story/internal/infrastructure"
+	"github.com/NordCoder/Story/internal/logger"
+	"github.com/NordCoder/Story/services/prefetch/category"
+	"go.uber.org/zap"
+)
+
+var _ infrastructure.FactRepository = (*FactRepository)(nil)
+
+type storedFact struct {
+	fact      entity.Fact
+	expiresAt time.Time // нулевое значение — без TTL
+}
+
+// FactRepository хранит факты в памяти процесса с той же семантикой, что и Redis-реализация:
+// TTL у фактов, FIFO-очередь ID и индекс по категориям. Подходит для тестов и
+// одноузлового запуска; данные не переживают перезапуск. С storage.backend=memory
+// приложение не подключается к Redis: остальные его хранилища тоже заменяются памятью.
+type FactRepository struct {
+	mu               sync.Mutex
+	ttl              time.Duration
+	now              func() time.Time
+	facts            map[entity.FactID]storedFact
+	queue            []entity.FactID
+	categories       map[entity.Category]map[entity.FactID]struct{}
+	categoryProvider category.Provider
+}
+
+type Option func(*FactRepository)
+
+func WithCategoryProvider(p category.Provider) Option {
+	return func(r *FactRepository) { r.categoryProvider = p }
+}
+
+// WithClock подменяет источник времени, чтобы проверять истечение TTL без ожидания.
+func WithClock(now func() time.Time) Option {
+	return func(r *FactRepository) { r.now = now }
+}
+
+// NewFactRepository конструктор; ttl <= 0 означает хранение без срока.
+func NewFactRepository(ttl time.Duration, opts ...Option) *FactRepository {
+	repo := &FactRepository{
+		ttl:        ttl,
+		now:        time.Now,
+		facts:      make(map[entity.FactID]storedFact),
+		categories: make(map[entity.Category]map[entity.FactID]struct{}),
+	}
+	for _, o := range opts {
+		o(repo)
+	}
+	return repo
+}
+
+// Save сохраняет факт, пушит его ID в очередь и индекс категории. Живой факт с тем же ID
+// не перезаписывается и в очередь заново не ставится: возвращается entity.ErrFactExists.
+func (r *FactRepository) Save(_ context.Context, f *entity.Fact) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.liveFact(f.ID); ok {
+		return entity.ErrFactExists
+	}
+
+	stored := storedFact{fact: *f}
+	if r.ttl > 0 {
+		stored.expiresAt = r.now().Add(r.ttl)
+	}
+	r.facts[f.ID] = stored
+	r.queue = append(r.queue, f.ID)
+
+	set, ok := r.categories[f.Category]
+	if !ok {
+		set = make(map[entity.FactID]struct{})
+		r.categories[f.Category] = set
+	}
+	set[f.ID] = struct{}{}
+	return nil
+}
+
+// SaveUnlisted сохраняет факт без очереди и индекса категории; живой факт с тем же ID
+// не перезаписывается: возвращается entity.ErrFactExists.
+func (r *FactRepository) SaveUnlisted(_ context.Context, f *entity.Fact) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.liveFact(f.ID); ok {
+		return entity.ErrFactExists
+	}
+
+	stored := storedFact{fact: *f}
+	if r.ttl > 0 {
+		stored.expiresAt = r.now().Add(r.ttl)
+	}
+	r.facts[f.ID] = stored
+	return nil
+}
+
+// GetByID достаёт факт по ID.
+func (r *FactRepository) GetByID(_ context.Context, id entity.FactID) (*entity.Fact, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, ok := r.liveFact(id)
+	if !ok {
+		return nil, entity.ErrFactNotFound
+	}
+	return f, nil
+}
+
+// GetByIDs возвращает найденные факты в порядке ids, пропуская отсутствующие.
+func (r *FactRepository) GetByIDs(_ context.Context, ids []entity.FactID) ([]*entity.Fact, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	facts := make([]*entity.Fact, 0, len(ids))
+	for _, id := range ids {
+		if f, ok := r.liveFact(id); ok {
+			facts = append(facts, f)
+		}
+	}
+	return facts, nil
+}
+
+// GetByCategory возвращает до count случайных фактов категории, попутно удаляя истёкшие ID.
+func (r *FactRepository) GetByCategory(ctx context.Context, category entity.Category, count int) ([]*entity.Fact, error) {
+	r.mu.Lock()
+	set := r.categories[category]
+	ids := make([]entity.FactID, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	rand.Shuffle(len(ids), func(i, j int) { ids[i], ids[j] = ids[j], ids[i] })
+	if len(ids) > count {
+		ids = ids[:count]
+	}
+
+	facts := make([]*entity.Fact, 0, len(ids))
+	for _, id := range ids {
+		f, ok := r.liveFact(id)
+		if !ok {
+			delete(set, id)
+			continue
+		}
+		facts = append(facts, f)
+	}
+	if len(set) == 0 {
+		delete(r.categories, category)
+	}
+	r.mu.Unlock()
+
+	if len(ids) == 0 {
+		if r.categoryProvider != nil {
+			logger.LoggerFromContext(ctx).Info("add category to provider: " + string(category))
+			if err := r.categoryProvider.AddCategory(ctx, category); err != nil {
+				logger.LoggerFromContext(ctx).Error("failed to add category to provider", zap.Error(err))
+				return nil, err
+			}
+		}
+		return nil, entity.ErrCategoryNotFound
+	}
+	return facts, nil
+}
+
+// PopRandom снимает с очереди самый старый живой факт; если таких нет, возвращает nil, nil.
+func (r *FactRepository) PopRandom(_ context.Context) (*entity.Fact, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for len(r.queue) > 0 {
+		id := r.queue[0]
+		// сдвиг вместо r.queue[1:]: иначе снятые ID держат память, пока append не перевыделит массив
+		n := copy(r.queue, r.queue[1:])
+		r.queue = r.queue[:n]
+		if f, ok := r.liveFact(id); ok {
+			return f, nil
+		}
+	}
+	return nil, nil
+}
+
+// CountFacts возвращает число живых фактов в очереди; истёкшие ID при этом вычищаются.
+func (r *FactRepository) CountFacts(_ context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	live := r.queue[:0]
+	for _, id := range r.queue {
+		if _, ok := r.liveFact(id); ok {
+			live = append(live, id)
+		}
+	}
+	r.queue = live
+	return int64(len(live)), nil
+}
+
+// Purge удаляет истёкшие факты вместе с их ID в очереди и индексах категорий и возвращает их число.
+// Без него факты, которые больше никто не запрашивает, живут в памяти до рестарта.
+func (r *FactRepository) Purge() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	purged := 0
+	for id, stored := range r.facts {
+		if !stored.expiresAt.IsZero() && !now.Before(stored.expiresAt) {
+			delete(r.facts, id)
+			purged++
+		}
+	}
+
+	// ID могли остаться и от фактов, удалённых раньше лениво, поэтому индексы чистятся всегда
+	live := r.queue[:0]
+	for _, id := range r.queue {
+		if _, ok := r.facts[id]; ok {
+			live = append(live, id)
+		}
+	}
+	for i := len(live); i < len(r.queue); i++ {
+		r.queue[i] = ""
+	}
+	r.queue = live
+
+	for category, set := range r.categories {
+		for id := range set {
+			if _, ok := r.facts[id]; !ok {
+				delete(set, id)
+			}
+		}
+		if len(set) == 0 {
+			delete(r.categories, category)
+		}
+	}
+	return purged
+}
+
+// Run вызывает Purge каждые interval, пока ctx не отменён.
+func (r *FactRepository) Run(ctx context.Context, interval time.Duration, log *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n := r.Purge(); n > 0 {
+				log.Debug("purged expired facts", zap.Int("count", n))
+			}
+		}
+	}
+}
+
+// Ping всегда успешен: хранилище живёт в памяти процесса.
+func (r *FactRepository) Ping(_ context.Context) error {
+	return nil
+}
+
+// liveFact вызывается под mu; истёкший факт удаляется из хранилища.
+func (r *FactRepository) liveFact(id entity.FactID) (*entity.Fact, bool) {
+	stored, ok := r.facts[id]
+	if !ok {
+		return nil, false
+	}
+	if !stored.expiresAt.IsZero() && !r.now().Before(stored.expiresAt) {
+		delete(r.facts, id)
+		return nil, false
+	}
+	f := stored.fact
+	return &f, true
+}
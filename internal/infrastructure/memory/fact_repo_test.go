@@ -0,0 +1,117 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NordCoder/Story/internal/entity"
+	"github.com/NordCoder/Story/internal/infrastructure"
+	"github.com/NordCoder/Story/internal/infrastructure/facttest"
+)
+
+// fakeClock — часы, которые двигаются только по advance.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestRepo(ttl time.Duration) (*FactRepository, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	return NewFactRepository(ttl, WithClock(clock.Now)), clock
+}
+
+func TestFactRepositoryContract(t *testing.T) {
+	facttest.Run(t, func(t *testing.T, ttl time.Duration) (infrastructure.FactRepository, func(time.Duration)) {
+		repo, clock := newTestRepo(ttl)
+		return repo, clock.advance
+	})
+}
+
+func TestPurgeRemovesExpiredFactsAndIndexes(t *testing.T) {
+	ctx := context.Background()
+	repo, clock := newTestRepo(time.Hour)
+
+	old := facttest.NewFact("history", 0)
+	if err := repo.Save(ctx, old); err != nil {
+		t.Fatal(err)
+	}
+	clock.advance(30 * time.Minute)
+	fresh := facttest.NewFact("art", 1)
+	if err := repo.Save(ctx, fresh); err != nil {
+		t.Fatal(err)
+	}
+	clock.advance(45 * time.Minute)
+
+	if n := repo.Purge(); n != 1 {
+		t.Errorf("Purge() = %d, want 1", n)
+	}
+	if _, ok := repo.facts[old.ID]; ok {
+		t.Error("expired fact is still stored")
+	}
+	if len(repo.queue) != 1 || repo.queue[0] != fresh.ID {
+		t.Errorf("queue = %v, want [%s]", repo.queue, fresh.ID)
+	}
+	if _, ok := repo.categories["history"]; ok {
+		t.Error("empty category set was not removed")
+	}
+	if _, ok := repo.categories["art"][fresh.ID]; !ok {
+		t.Error("live fact was removed from its category set")
+	}
+}
+
+func TestPurgeDropsIndexesOfLazilyDeletedFacts(t *testing.T) {
+	ctx := context.Background()
+	repo, clock := newTestRepo(time.Hour)
+
+	f := facttest.NewFact("history", 0)
+	if err := repo.Save(ctx, f); err != nil {
+		t.Fatal(err)
+	}
+	clock.advance(2 * time.Hour)
+	// GetByID удаляет истёкший факт, но его ID остаются в очереди и категории
+	if _, err := repo.GetByID(ctx, f.ID); err != entity.ErrFactNotFound {
+		t.Fatalf("GetByID() error = %v, want ErrFactNotFound", err)
+	}
+
+	if n := repo.Purge(); n != 0 {
+		t.Errorf("Purge() = %d, want 0: the fact was already deleted", n)
+	}
+	if len(repo.queue) != 0 || len(repo.categories) != 0 {
+		t.Errorf("indexes left after purge: queue=%v categories=%v", repo.queue, repo.categories)
+	}
+}
+
+func TestPopRandomReusesQueueArray(t *testing.T) {
+	ctx := context.Background()
+	repo, _ := newTestRepo(time.Hour)
+	for i := 0; i < 4; i++ {
+		if err := repo.Save(ctx, facttest.NewFact("history", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	before := cap(repo.queue)
+
+	for i := 0; i < 4; i++ {
+		if _, err := repo.PopRandom(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// срез не уезжает вперёд по массиву, так что его ёмкость сохраняется для новых ID
+	if cap(repo.queue) != before {
+		t.Errorf("queue capacity = %d after pops, want %d", cap(repo.queue), before)
+	}
+}
@@ -0,0 +1,185 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/NordCoder/Story/internal/entity"
+	"github.com/NordCoder/Story/internal/infrastructure"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ infrastructure.FactArchive = (*FactArchive)(nil)
+
+// FactArchive хранит в Postgres каждый сохранённый префетчером факт и число его показов.
+// В отличие от Redis, записи не истекают: по архиву можно восстановить кэш.
+type FactArchive struct {
+	db *pgxpool.Pool
+}
+
+func NewFactArchive(pool *pgxpool.Pool) *FactArchive {
+	return &FactArchive{db: pool}
+}
+
+// Archive сохраняет факт и возвращает ID, под которым он лежит в архиве. Статья уникальна
+// по source_url: если она уже заархивирована, запись не меняется и возвращается её прежний ID,
+// чтобы показы и реакции продолжали копиться на одном факте.
+func (a *FactArchive) Archive(ctx context.Context, f *entity.Fact) (entity.FactID, error) {
+	// параллельная вставка той же статьи может закоммититься между INSERT и SELECT
+	// одного запроса, тогда он не вернёт строк; повторный запрос её уже увидит
+	for attempt := 0; ; attempt++ {
+		var id string
+		err := a.db.QueryRow(ctx,
+			`WITH inserted AS (
+			     INSERT INTO facts_archive (id, category, title, summary, image_url, source_url, lang, fetched_at)
+			     VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			     ON CONFLICT (source_url) DO NOTHING
+			     RETURNING id
+			 )
+			 SELECT id::text FROM inserted
+			 UNION ALL
+			 SELECT id::text FROM facts_archive WHERE source_url = $6
+			 LIMIT 1`,
+			string(f.ID), string(f.Category), f.Title, f.Summary, f.ImageURL, f.SourceURL, f.Lang, f.FetchedAt).Scan(&id)
+		if errors.Is(err, pgx.ErrNoRows) && attempt == 0 {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("archive fact: %w", err)
+		}
+		return entity.FactID(id), nil
+	}
+}
+
+// MarkServed увеличивает счётчик показов факта. Факты, которых нет в архиве, пропускаются.
+func (a *FactArchive) MarkServed(ctx context.Context, id entity.FactID) error {
+	_, err := a.db.Exec(ctx,
+		`UPDATE facts_archive SET serve_count = serve_count + 1, last_served_at = NOW()
+		 WHERE id = $1`,
+		string(id))
+	if err != nil {
+		return fmt.Errorf("mark fact served: %w", err)
+	}
+	return nil
+}
+
+// GetByCategory возвращает до limit фактов категории, начиная с реже всего показанных.
+func (a *FactArchive) GetByCategory(ctx context.Context, category entity.Category, limit int) ([]*entity.Fact, error) {
+	rows, err := a.db.Query(ctx,
+		`SELECT id, category, title, summary, image_url, source_url, lang, fetched_at
+		 FROM facts_archive
+		 WHERE category = $1
+		 ORDER BY serve_count, random()
+		 LIMIT $2`,
+		string(category), limit)
+	if err != nil {
+		return nil, fmt.Errorf("query archived facts: %w", err)
+	}
+	defer rows.Close()
+
+	var facts []*entity.Fact
+	for rows.Next() {
+		var f entity.Fact
+		if err := rows.Scan(&f.ID, &f.Category, &f.Title, &f.Summary, &f.ImageURL, &f.SourceURL, &f.Lang, &f.FetchedAt); err != nil {
+			return nil, fmt.Errorf("scan archived fact: %w", err)
+		}
+		facts = append(facts, &f)
+	}
+	return facts, rows.Err()
+}
+
+// Ping проверяет соединение с Postgres.
+func (a *FactArchive) Ping(ctx context.Context) error {
+	return a.db.Ping(ctx)
+}
+
+var _ infrastructure.FactSearcher = (*FactArchive)(nil)
+
+// searchWhere — условия поиска без совпадения с запросом (его даёт searchMatch); $2 — категория,
+// $3 — язык, $4 — пользователь, чьи показы ограничивают выдачу (NULL — без ограничения).
+const searchWhere = `
+	AND ($2 = '' OR a.category = $2)
+	AND ($3 = '' OR a.lang = $3)
+	AND ($4::uuid IS NULL OR EXISTS (
+		SELECT 1 FROM fact_serves s WHERE s.user_id = $4::uuid AND s.fact_id = a.id))`
+
+// Search ищет по заголовку и тексту фактов архива ($1 — запрос). Факты, которых в архиве нет, —
+// загруженные по ссылкам статей или не записанные из-за ошибки архива — в выдачу не попадают,
+// даже если они есть в Redis.
+func (a *FactArchive) Search(ctx context.Context, q entity.FactQuery) ([]entity.FactHit, int, error) {
+	var seenBy *string
+	if q.SeenBy != "" {
+		seenBy = &q.SeenBy
+	}
+	args := []interface{}{q.Text, string(q.Category), q.Lang, seenBy}
+	where := searchMatch(q.Lang) + searchWhere
+
+	var total int
+	if err := a.db.QueryRow(ctx,
+		`SELECT COUNT(*) FROM facts_archive a WHERE `+where,
+		args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count search results: %w", err)
+	}
+	if total == 0 || q.Offset >= total {
+		return nil, total, nil
+	}
+
+	rows, err := a.db.Query(ctx,
+		`SELECT a.id, a.category, a.title, a.summary, a.image_url, a.source_url, a.lang, a.fetched_at,
+		        ts_rank_cd(a.search, websearch_to_tsquery(a.search_config, $1))::float8 AS rank,
+		        ts_headline(a.search_config, a.summary, websearch_to_tsquery(a.search_config, $1),
+		                    'MaxFragments=1, MaxWords=30, MinWords=10, StartSel=«, StopSel=»')
+		 FROM facts_archive a
+		 WHERE `+where+`
+		 ORDER BY rank DESC, a.fetched_at DESC, a.id
+		 LIMIT $5 OFFSET $6`,
+		append(args, q.Limit, q.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("search facts: %w", err)
+	}
+	defer rows.Close()
+
+	var hits []entity.FactHit
+	for rows.Next() {
+		var h entity.FactHit
+		f := &h.Fact
+		if err := rows.Scan(&f.ID, &f.Category, &f.Title, &f.Summary, &f.ImageURL, &f.SourceURL, &f.Lang, &f.FetchedAt, &h.Rank, &h.Snippet); err != nil {
+			return nil, 0, fmt.Errorf("scan search hit: %w", err)
+		}
+		hits = append(hits, h)
+	}
+	return hits, total, rows.Err()
+}
+
+// searchConfigs — конфигурации разбора, которыми индексируется архив (см. миграцию 013).
+var searchConfigs = []string{"english", "russian", "simple"}
+
+// searchConfig возвращает конфигурацию, которой индексируются факты языка lang;
+// должна совпадать с колонкой search_config из миграции 013.
+func searchConfig(lang string) string {
+	switch lang {
+	case "en":
+		return "english"
+	case "ru":
+		return "russian"
+	default:
+		return "simple"
+	}
+}
+
+// searchMatch — условие совпадения факта с запросом $1. С фильтром по языку запрос разбирается
+// конфигурацией этого языка. Без фильтра каждая конфигурация проверяется только на фактах,
+// проиндексированных ею: условия остаются константными, и GIN-индекс по search работает.
+func searchMatch(lang string) string {
+	if lang != "" {
+		return fmt.Sprintf("a.search @@ websearch_to_tsquery('%s', $1)", searchConfig(lang))
+	}
+	parts := make([]string, len(searchConfigs))
+	for i, cfg := range searchConfigs {
+		parts[i] = fmt.Sprintf("(a.search_config = '%s' AND a.search @@ websearch_to_tsquery('%s', $1))", cfg, cfg)
+	}
+	return "(" + strings.Join(parts, " OR ") + ")"
+}
@@ -0,0 +1,100 @@
+//go:build integration_test
+
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/NordCoder/Story/internal/entity"
+	"github.com/NordCoder/Story/internal/infrastructure/pgtest"
+)
+
+// archiveFacts кладёт факты в архив под уникальной категорией и удаляет их после теста.
+func archiveFacts(t *testing.T, archive *FactArchive, facts ...*entity.Fact) entity.Category {
+	t.Helper()
+	category := entity.Category(pgtest.Name("search"))
+	t.Cleanup(func() {
+		pgtest.Exec(t, archive.db, `DELETE FROM facts_archive WHERE category = $1`, string(category))
+	})
+	for _, f := range facts {
+		f.ID = entity.NewFactID()
+		f.Category = category
+		f.SourceURL = "https://example.org/" + pgtest.Name("article")
+		f.FetchedAt = time.Now()
+		if _, err := archive.Archive(context.Background(), f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return category
+}
+
+func searchTitles(t *testing.T, archive *FactArchive, q entity.FactQuery) []string {
+	t.Helper()
+	q.Limit = 10
+	hits, total, err := archive.Search(context.Background(), q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != len(hits) {
+		t.Errorf("Search(%q) total = %d, returned %d hits", q.Text, total, len(hits))
+	}
+	titles := make([]string, len(hits))
+	for i, h := range hits {
+		titles[i] = h.Fact.Title
+	}
+	return titles
+}
+
+func TestSearchRanksTitleMatchesFirst(t *testing.T) {
+	archive := NewFactArchive(pgtest.NewPool(t))
+	category := archiveFacts(t, archive,
+		&entity.Fact{Title: "Mountains", Summary: "The range ends at the ocean shore.", Lang: "en"},
+		&entity.Fact{Title: "Oceans", Summary: "An ocean covers most of the planet.", Lang: "en"},
+		&entity.Fact{Title: "Deserts", Summary: "Dry land with little rain.", Lang: "en"},
+	)
+
+	got := searchTitles(t, archive, entity.FactQuery{Text: "ocean", Category: category, Lang: "en"})
+	if len(got) != 2 || got[0] != "Oceans" || got[1] != "Mountains" {
+		t.Errorf("Search(ocean) = %v, want [Oceans Mountains]", got)
+	}
+}
+
+func TestSearchUsesEachArticleLanguage(t *testing.T) {
+	archive := NewFactArchive(pgtest.NewPool(t))
+	category := archiveFacts(t, archive,
+		&entity.Fact{Title: "Океаны", Summary: "Мировой океан покрывает большую часть планеты.", Lang: "ru"},
+		&entity.Fact{Title: "Oceans", Summary: "An ocean covers most of the planet.", Lang: "en"},
+		&entity.Fact{Title: "Ozeane", Summary: "Die Ozeane bedecken den Planeten.", Lang: "de"},
+		&entity.Fact{Title: "Без языка", Summary: "Ozeane ohne Sprachangabe.", Lang: ""},
+	)
+
+	tests := []struct {
+		name string
+		q    entity.FactQuery
+		want []string
+	}{
+		// стемминг по языку статьи: запрос без фильтра находит формы слова в обоих языках
+		{"russian stem without lang", entity.FactQuery{Text: "океанов"}, []string{"Океаны"}},
+		{"english stem without lang", entity.FactQuery{Text: "oceans"}, []string{"Oceans"}},
+		// статьи на прочих языках и без языка разбираются simple: слово ищется как есть
+		{"unknown lang via simple", entity.FactQuery{Text: "Ozeane", Lang: "de"}, []string{"Ozeane"}},
+		{"simple without lang", entity.FactQuery{Text: "Ozeane"}, []string{"Ozeane", "Без языка"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.q.Category = category
+			got := searchTitles(t, archive, tt.q)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Search(%q) = %v, want %v", tt.q.Text, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Search(%q) = %v, want %v", tt.q.Text, got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSearchConfigFallsBackToSimple(t *testing.T) {
+	tests := []struct {
+		lang string
+		want string
+	}{
+		{"en", "english"},
+		{"ru", "russian"},
+		{"de", "simple"},
+		{"", "simple"},
+	}
+	for _, tt := range tests {
+		if got := searchConfig(tt.lang); got != tt.want {
+			t.Errorf("searchConfig(%q) = %q, want %q", tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestSearchMatchWithoutLangUsesEachRowConfig(t *testing.T) {
+	match := searchMatch("")
+	for _, cfg := range searchConfigs {
+		want := "(a.search_config = '" + cfg + "' AND a.search @@ websearch_to_tsquery('" + cfg + "', $1))"
+		if !strings.Contains(match, want) {
+			t.Errorf("searchMatch(\"\") = %s, missing %s", match, want)
+		}
+	}
+
+	if got, want := searchMatch("de"), "a.search @@ websearch_to_tsquery('simple', $1)"; got != want {
+		t.Errorf("searchMatch(de) = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/NordCoder/Story/internal/entity"
+	"github.com/NordCoder/Story/internal/infrastructure"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ infrastructure.ServeLog = (*ServeLog)(nil)
+
+// ServeLog пишет показы фактов в fact_serves и сопоставляет их с реакциями и событиями.
+type ServeLog struct {
+	db *pgxpool.Pool
+}
+
+func NewServeLog(pool *pgxpool.Pool) *ServeLog {
+	return &ServeLog{db: pool}
+}
+
+// Record сохраняет показ факта.
+func (l *ServeLog) Record(ctx context.Context, s entity.ServedFact) error {
+	variants := s.Variants
+	if variants == nil {
+		variants = map[string]string{}
+	}
+	raw, err := json.Marshal(variants)
+	if err != nil {
+		return fmt.Errorf("marshal variants: %w", err)
+	}
+	_, err = l.db.Exec(ctx,
+		`INSERT INTO fact_serves (user_id, fact_id, category, reason, variants)
+		 VALUES ($1, $2, $3, $4, $5)`,
+		s.UserID, string(s.FactID), string(s.Category), s.Reason, raw)
+	if err != nil {
+		return fmt.Errorf("record fact serve: %w", err)
+	}
+	return nil
+}
+
+// Report считает вовлечённость по вариантам эксперимента. Реакцией на показ считается первая
+// реакция пользователя на этот факт после показа; вовлечённостью — хотя бы одно открытие
+// источника, разворот текста или шаринг после показа.
+func (l *ServeLog) Report(ctx context.Context, experiment string, since time.Time) ([]entity.VariantStats, error) {
+	rows, err := l.db.Query(ctx,
+		`SELECT s.variants->>$1 AS variant,
+		        COUNT(DISTINCT s.user_id),
+		        COUNT(*),
+		        COUNT(*) FILTER (WHERE r.reaction = 'like'),
+		        COUNT(*) FILTER (WHERE r.reaction = 'dislike'),
+		        COUNT(*) FILTER (WHERE r.reaction = 'skip'),
+		        COUNT(*) FILTER (WHERE e.engaged),
+		        COALESCE(AVG(r.dwell_ms), 0)::float8
+		 FROM fact_serves s
+		 LEFT JOIN LATERAL (
+		     SELECT fr.reaction, fr.dwell_ms
+		     FROM fact_reactions fr
+		     WHERE fr.user_id = s.user_id AND fr.fact_id = s.fact_id AND fr.created_at >= s.served_at
+		     ORDER BY fr.created_at
+		     LIMIT 1
+		 ) r ON TRUE
+		 LEFT JOIN LATERAL (
+		     SELECT TRUE AS engaged
+		     FROM user_events ue
+		     WHERE ue.user_id = s.user_id AND ue.fact_id = s.fact_id AND ue.occurred_at >= s.served_at
+		       AND ue.event_type IN ('open_source', 'expand_summary', 'share')
+		     LIMIT 1
+		 ) e ON TRUE
+		 WHERE s.served_at >= $2 AND s.variants ? $1
+		 GROUP BY variant
+		 ORDER BY variant`,
+		experiment, since)
+	if err != nil {
+		return nil, fmt.Errorf("query experiment report: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []entity.VariantStats
+	for rows.Next() {
+		var v entity.VariantStats
+		var dwellMs float64
+		if err := rows.Scan(&v.Variant, &v.Users, &v.Serves, &v.Likes, &v.Dislikes, &v.Skips, &v.Engaged, &dwellMs); err != nil {
+			return nil, fmt.Errorf("scan variant stats: %w", err)
+		}
+		v.AvgDwell = time.Duration(dwellMs * float64(time.Millisecond))
+		stats = append(stats, v)
+	}
+	return stats, rows.Err()
+}
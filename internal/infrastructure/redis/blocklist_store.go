@@ -0,0 +1,49 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/NordCoder/Story/internal/entity"
+	"github.com/NordCoder/Story/services/prefetch/category"
+	"github.com/go-redis/redis/v8"
+)
+
+var _ category.BlocklistStore = (*BlocklistStore)(nil)
+
+// BlocklistStore хранит заблокированные категории в множестве Redis, общем для всех реплик.
+type BlocklistStore struct {
+	client redis.UniversalClient
+	key    string
+}
+
+// NewBlocklistStore создаёт хранилище блокировок. Множество одно, поэтому hash tag ему не нужен.
+func NewBlocklistStore(client redis.UniversalClient) *BlocklistStore {
+	return &BlocklistStore{client: client, key: "category_blocklist"}
+}
+
+func (s *BlocklistStore) Load(ctx context.Context) ([]entity.Category, error) {
+	members, err := s.client.SMembers(ctx, s.key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis SMEMBERS: %w", err)
+	}
+	categories := make([]entity.Category, len(members))
+	for i, m := range members {
+		categories[i] = entity.Category(m)
+	}
+	return categories, nil
+}
+
+func (s *BlocklistStore) Add(ctx context.Context, c entity.Category) error {
+	if err := s.client.SAdd(ctx, s.key, string(c)).Err(); err != nil {
+		return fmt.Errorf("redis SADD: %w", err)
+	}
+	return nil
+}
+
+func (s *BlocklistStore) Remove(ctx context.Context, c entity.Category) error {
+	if err := s.client.SRem(ctx, s.key, string(c)).Err(); err != nil {
+		return fmt.Errorf("redis SREM: %w", err)
+	}
+	return nil
+}
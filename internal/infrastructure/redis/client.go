@@ -3,13 +3,15 @@ package redis
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/NordCoder/Story/config"
 	"github.com/go-redis/redis/v8"
 )
 
-func NewRedisClient() (*redis.Client, error) {
+// NewRedisClient создаёт клиент под режим из redis.yaml: одиночный узел, sentinel или кластер.
+func NewRedisClient() (redis.UniversalClient, error) {
 	cfg := config.NewRedisConfig()
 
 	dialTO, _ := time.ParseDuration(cfg.DialTimeout)
@@ -17,10 +19,12 @@ func NewRedisClient() (*redis.Client, error) {
 	writeTO, _ := time.ParseDuration(cfg.WriteTimeout)
 	poolTO, _ := time.ParseDuration(cfg.PoolTimeout)
 
-	rdb := redis.NewClient(&redis.Options{
-		Addr:     fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
-		Password: cfg.Password,
-		DB:       cfg.DB,
+	opts := &redis.UniversalOptions{
+		Addrs:            cfg.Addrs,
+		MasterName:       cfg.MasterName,
+		Password:         cfg.Password,
+		SentinelPassword: cfg.SentinelPassword,
+		DB:               cfg.DB,
 
 		DialTimeout:  dialTO,
 		ReadTimeout:  readTO,
@@ -29,7 +33,19 @@ func NewRedisClient() (*redis.Client, error) {
 		PoolSize:     cfg.PoolSize,
 		MinIdleConns: cfg.MinIdleConns,
 		PoolTimeout:  poolTO,
-	})
+	}
+
+	// режим выбирается явно: NewUniversalClient счёл бы кластер из одного seed-узла одиночным Redis
+	var rdb redis.UniversalClient
+	switch cfg.Mode {
+	case config.RedisModeSentinel:
+		rdb = redis.NewFailoverClient(opts.Failover())
+	case config.RedisModeCluster:
+		rdb = redis.NewClusterClient(opts.Cluster())
+	default:
+		opts.Addrs = []string{fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)}
+		rdb = redis.NewClient(opts.Simple())
+	}
 
 	pingTO, _ := time.ParseDuration(cfg.PingTimeout)
 	ctx, cancel := context.WithTimeout(context.Background(), pingTO)
@@ -39,3 +55,43 @@ func NewRedisClient() (*redis.Client, error) {
 	}
 	return rdb, nil
 }
+
+// HashTagged добавляет к шаблону ключа префикс {tag}:, чтобы все такие ключи попали в один слот кластера.
+// Пустой tag оставляет шаблон без изменений.
+func HashTagged(tag, pattern string) string {
+	if tag == "" {
+		return pattern
+	}
+	return "{" + tag + "}:" + pattern
+}
+
+// scanKeys вызывает fn для каждого ключа по шаблону. В кластере SCAN видит только один узел,
+// поэтому обходятся все мастера; вызовы fn сериализуются.
+func scanKeys(ctx context.Context, client redis.UniversalClient, pattern string, count int64, fn func(key string) error) error {
+	cluster, ok := client.(*redis.ClusterClient)
+	if !ok {
+		return scanNode(ctx, client, pattern, count, fn)
+	}
+
+	var mu sync.Mutex
+	return cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+		return scanNode(ctx, node, pattern, count, func(key string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			return fn(key)
+		})
+	})
+}
+
+func scanNode(ctx context.Context, client redis.Cmdable, pattern string, count int64, fn func(key string) error) error {
+	iter := client.Scan(ctx, 0, pattern, count).Iterator()
+	for iter.Next(ctx) {
+		if err := fn(iter.Val()); err != nil {
+			return err
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("redis SCAN: %w", err)
+	}
+	return nil
+}
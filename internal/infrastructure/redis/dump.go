@@ -0,0 +1,306 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/NordCoder/Story/internal/entity"
+	"github.com/go-redis/redis/v8"
+)
+
+// Виды записей дампа. В файле сначала идут все факты, затем очередь от головы к хвосту,
+// затем индексы категорий: при импорте очередь и индексы восстанавливаются только
+// для фактов, загруженных выше по файлу.
+const (
+	dumpKindFact     = "fact"
+	dumpKindQueue    = "queue"
+	dumpKindCategory = "category"
+)
+
+// dumpRecord — одна строка JSONL-дампа.
+type dumpRecord struct {
+	Kind string `json:"kind"`
+
+	// fact: факт в том же JSON, что пишет Save, и момент истечения (nil — без TTL).
+	Fact      json.RawMessage `json:"fact,omitempty"`
+	ExpiresAt *time.Time      `json:"expires_at,omitempty"`
+
+	// queue и category: ID факта и, для category, имя категории.
+	ID       entity.FactID   `json:"id,omitempty"`
+	Category entity.Category `json:"category,omitempty"`
+}
+
+// DumpReport — сколько записей выгружено или загружено.
+type DumpReport struct {
+	Facts      int
+	Queue      int
+	Categories int
+	// Skipped — факты, уже существующие в Redis или истёкшие к моменту импорта, и ссылки на них.
+	Skipped int
+}
+
+const dumpBatch = 500
+
+// Export выгружает в w все факты с их TTL, порядок feed_queue и индексы категорий.
+// Снимок не атомарен: изменения, сделанные во время выгрузки, могут попасть в него частично.
+func (r *FactRepository) Export(ctx context.Context, w io.Writer) (DumpReport, error) {
+	var report DumpReport
+	enc := json.NewEncoder(w)
+
+	factPrefix, factSuffix := splitKeyPattern(r.keyFact)
+	keys := make([]string, 0, dumpBatch)
+	flush := func() error {
+		n, err := r.exportFacts(ctx, enc, keys, factPrefix, factSuffix)
+		report.Facts += n
+		keys = keys[:0]
+		return err
+	}
+
+	err := scanKeys(ctx, r.client, fmt.Sprintf(r.keyFact, "*"), dumpBatch, func(key string) error {
+		keys = append(keys, key)
+		if len(keys) >= dumpBatch {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+	if err := flush(); err != nil {
+		return report, err
+	}
+
+	for start := int64(0); ; start += dumpBatch {
+		ids, err := r.client.LRange(ctx, r.keyFeedQueue, start, start+dumpBatch-1).Result()
+		if err != nil {
+			return report, fmt.Errorf("redis LRANGE: %w", err)
+		}
+		if len(ids) == 0 {
+			break
+		}
+		for _, id := range ids {
+			if err := enc.Encode(dumpRecord{Kind: dumpKindQueue, ID: entity.FactID(id)}); err != nil {
+				return report, fmt.Errorf("write queue record: %w", err)
+			}
+			report.Queue++
+		}
+	}
+
+	catPrefix, catSuffix := splitKeyPattern(r.keyCategorySet)
+	err = scanKeys(ctx, r.client, r.categoryKey("*"), dumpBatch, func(key string) error {
+		category := entity.Category(strings.TrimSuffix(strings.TrimPrefix(key, catPrefix), catSuffix))
+
+		members := r.client.SScan(ctx, key, 0, "", dumpBatch).Iterator()
+		for members.Next(ctx) {
+			rec := dumpRecord{Kind: dumpKindCategory, ID: entity.FactID(members.Val()), Category: category}
+			if err := enc.Encode(rec); err != nil {
+				return fmt.Errorf("write category record: %w", err)
+			}
+			report.Categories++
+		}
+		if err := members.Err(); err != nil {
+			return fmt.Errorf("redis SSCAN: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+// exportFacts одним пайплайном читает значения и PTTL ключей фактов и пишет их в enc.
+// Ключи, истёкшие между SCAN и чтением, пропускаются.
+func (r *FactRepository) exportFacts(ctx context.Context, enc *json.Encoder, keys []string, prefix, suffix string) (int, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	pipe := r.client.Pipeline()
+	gets := make([]*redis.StringCmd, len(keys))
+	ttls := make([]*redis.DurationCmd, len(keys))
+	for i, key := range keys {
+		gets[i] = pipe.Get(ctx, key)
+		ttls[i] = pipe.PTTL(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return 0, fmt.Errorf("redis GET/PTTL pipeline: %w", err)
+	}
+
+	now := time.Now()
+	written := 0
+	for i, key := range keys {
+		data, err := gets[i].Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return written, fmt.Errorf("redis GET %s: %w", key, err)
+		}
+
+		rec := dumpRecord{
+			Kind: dumpKindFact,
+			Fact: json.RawMessage(data),
+			ID:   entity.FactID(strings.TrimSuffix(strings.TrimPrefix(key, prefix), suffix)),
+		}
+		// PTTL возвращает отрицательное значение для ключей без TTL
+		if ttl := ttls[i].Val(); ttl > 0 {
+			expiresAt := now.Add(ttl)
+			rec.ExpiresAt = &expiresAt
+		}
+		if err := enc.Encode(rec); err != nil {
+			return written, fmt.Errorf("write fact record: %w", err)
+		}
+		written++
+	}
+	return written, nil
+}
+
+// Import загружает дамп, созданный Export. Истёкшие к моменту импорта факты и факты,
+// ID которых уже есть в Redis, пропускаются вместе со ссылками на них из очереди и индексов.
+// Импортированные ID дописываются в хвост очереди в исходном порядке, то есть будут выданы раньше уже лежащих там.
+// Команды уходят в Redis пайплайнами по dumpBatch записей.
+func (r *FactRepository) Import(ctx context.Context, rd io.Reader) (DumpReport, error) {
+	im := &dumpImporter{
+		repo:     r,
+		pipe:     r.client.Pipeline(),
+		imported: make(map[entity.FactID]struct{}),
+	}
+
+	scanner := bufio.NewScanner(rd)
+	// факт с длинным summary не помещается в буфер по умолчанию
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var rec dumpRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return im.report, fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := im.add(ctx, rec); err != nil {
+			return im.report, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return im.report, fmt.Errorf("read dump: %w", err)
+	}
+
+	return im.report, im.flush(ctx)
+}
+
+// dumpImporter копит команды импорта в пайплайне и отправляет их пачками.
+// Перед ссылкой из очереди или индекса пайплайн с фактами отправляется, чтобы знать,
+// какие из них действительно записаны.
+type dumpImporter struct {
+	repo     *FactRepository
+	pipe     redis.Pipeliner
+	imported map[entity.FactID]struct{}
+	report   DumpReport
+
+	// команды текущей пачки
+	commands   int
+	facts      []pendingFact
+	queue      int
+	categories int
+}
+
+type pendingFact struct {
+	id  entity.FactID
+	cmd *redis.BoolCmd
+}
+
+func (im *dumpImporter) add(ctx context.Context, rec dumpRecord) error {
+	r := im.repo
+	switch rec.Kind {
+	case dumpKindFact:
+		data, ttl, ok, err := factToImport(rec)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			im.report.Skipped++
+			return nil
+		}
+		im.facts = append(im.facts, pendingFact{id: rec.ID, cmd: im.pipe.SetNX(ctx, r.factKey(rec.ID), data, ttl)})
+	case dumpKindQueue, dumpKindCategory:
+		if len(im.facts) > 0 {
+			if err := im.flush(ctx); err != nil {
+				return err
+			}
+		}
+		if _, ok := im.imported[rec.ID]; !ok {
+			im.report.Skipped++
+			return nil
+		}
+		if rec.Kind == dumpKindQueue {
+			im.pipe.RPush(ctx, r.keyFeedQueue, string(rec.ID))
+			im.queue++
+		} else {
+			im.pipe.SAdd(ctx, r.categoryKey(string(rec.Category)), string(rec.ID))
+			im.categories++
+		}
+	default:
+		return fmt.Errorf("unknown record kind %q", rec.Kind)
+	}
+
+	im.commands++
+	if im.commands >= dumpBatch {
+		return im.flush(ctx)
+	}
+	return nil
+}
+
+// flush отправляет накопленные команды и учитывает их в отчёте.
+func (im *dumpImporter) flush(ctx context.Context) error {
+	if im.commands == 0 {
+		return nil
+	}
+	_, err := im.pipe.Exec(ctx)
+	im.commands = 0
+	if err != nil {
+		im.facts, im.queue, im.categories = im.facts[:0], 0, 0
+		return fmt.Errorf("redis import pipeline: %w", err)
+	}
+
+	for _, f := range im.facts {
+		if f.cmd.Val() {
+			im.imported[f.id] = struct{}{}
+			im.report.Facts++
+		} else {
+			im.report.Skipped++
+		}
+	}
+	im.report.Queue += im.queue
+	im.report.Categories += im.categories
+	im.facts, im.queue, im.categories = im.facts[:0], 0, 0
+	return nil
+}
+
+// factToImport проверяет запись факта и возвращает его JSON с оставшимся TTL.
+// ok = false, если факт уже истёк.
+func factToImport(rec dumpRecord) (data []byte, ttl time.Duration, ok bool, err error) {
+	var f entity.Fact
+	if err := json.Unmarshal(rec.Fact, &f); err != nil {
+		return nil, 0, false, fmt.Errorf("unmarshal fact: %w", err)
+	}
+	if f.ID == "" || f.ID != rec.ID {
+		return nil, 0, false, fmt.Errorf("fact id %q does not match record id %q", f.ID, rec.ID)
+	}
+
+	if rec.ExpiresAt != nil {
+		ttl = time.Until(*rec.ExpiresAt)
+		if ttl <= 0 {
+			return nil, 0, false, nil
+		}
+	}
+	return []byte(rec.Fact), ttl, true, nil
+}
@@ -0,0 +1,104 @@
+//go:build integration_test
+
+package redis
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/NordCoder/Story/internal/entity"
+	"github.com/NordCoder/Story/internal/infrastructure/facttest"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+	tag := newTestTag(t, client)
+	repo := newTestRepo(client, tag, time.Hour)
+
+	facts := make([]*entity.Fact, 5)
+	for i := range facts {
+		facts[i] = facttest.NewFact("dump", i)
+		if err := repo.Save(ctx, facts[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a, b, c, e := facts[0], facts[1], facts[2], facts[4]
+	// a снят с очереди, но остаётся в индексе; у b свой TTL, у e TTL нет
+	if _, err := repo.PopRandom(ctx); err != nil {
+		t.Fatal(err)
+	}
+	client.PExpire(ctx, repo.factKey(b.ID), 10*time.Minute)
+	client.Persist(ctx, repo.factKey(e.ID))
+	queueBefore := client.LRange(ctx, repo.keyFeedQueue, 0, -1).Val()
+
+	var dump bytes.Buffer
+	exported, err := repo.Export(ctx, &dump)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exported.Facts != 5 || exported.Queue != 4 || exported.Categories != 5 {
+		t.Errorf("Export() = %+v, want 5 facts, 4 queue entries, 5 category entries", exported)
+	}
+
+	// факт, истёкший к моменту импорта, и ссылка на него из очереди
+	expired := facttest.NewFact("dump", 5)
+	expiredData, _ := json.Marshal(expired)
+	past := time.Now().Add(-time.Minute)
+	enc := json.NewEncoder(&dump)
+	_ = enc.Encode(dumpRecord{Kind: dumpKindFact, ID: expired.ID, Fact: expiredData, ExpiresAt: &past})
+	_ = enc.Encode(dumpRecord{Kind: dumpKindQueue, ID: expired.ID})
+
+	// «сбрасываем» Redis; c к моменту импорта уже сохранён заново
+	for _, key := range client.Keys(ctx, HashTagged(tag, "*")).Val() {
+		client.Del(ctx, key)
+	}
+	if err := repo.Save(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+
+	imported, err := repo.Import(ctx, &dump)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// пропущены: факт c, его записи в очереди и индексе, истёкший факт и его запись в очереди
+	want := DumpReport{Facts: 4, Queue: 3, Categories: 4, Skipped: 5}
+	if imported != want {
+		t.Errorf("Import() = %+v, want %+v", imported, want)
+	}
+	assertConsistent(t, client, repo)
+
+	// очередь: уже лежавший c, затем импортированные ID в исходном порядке
+	wantQueue := []string{string(c.ID)}
+	for _, id := range queueBefore {
+		if id != string(c.ID) {
+			wantQueue = append(wantQueue, id)
+		}
+	}
+	if got := client.LRange(ctx, repo.keyFeedQueue, 0, -1).Val(); !slices.Equal(got, wantQueue) {
+		t.Errorf("queue after import = %v, want %v", got, wantQueue)
+	}
+	if !client.SIsMember(ctx, repo.categoryKey("dump"), string(a.ID)).Val() {
+		t.Error("popped fact a is missing from its category index after import")
+	}
+
+	if ttl := client.PTTL(ctx, repo.factKey(b.ID)).Val(); ttl <= 9*time.Minute || ttl > 10*time.Minute {
+		t.Errorf("TTL of b after import = %s, want about 10m", ttl)
+	}
+	if ttl := client.PTTL(ctx, repo.factKey(a.ID)).Val(); ttl <= 59*time.Minute || ttl > time.Hour {
+		t.Errorf("TTL of a after import = %s, want about 1h", ttl)
+	}
+	if n := client.Exists(ctx, repo.factKey(e.ID)).Val(); n != 1 {
+		t.Fatal("fact without TTL was not imported")
+	}
+	if ttl := client.PTTL(ctx, repo.factKey(e.ID)).Val(); ttl >= 0 {
+		t.Errorf("TTL of e after import = %s, want none", ttl)
+	}
+	if n := client.Exists(ctx, repo.factKey(expired.ID)).Val(); n != 0 {
+		t.Error("expired fact was imported")
+	}
+}
@@ -16,30 +16,38 @@ import (
 )
 
 // FactRepository реализует хранение фактов в Redis.
-// Все операции выполняются напрямую через r.client.
+// Все операции выполняются напрямую через r.client; client может быть одиночным узлом,
+// sentinel-клиентом или кластером. В кластере нужен WithHashTag: Save и скрипты
+// работают сразу с ключом факта, очередью и индексом категории.
 type FactRepository struct {
-	client           *redis.Client
+	client           redis.UniversalClient
+	hashTag          string
 	ttl              time.Duration
 	keyFact          string // шаблон "fact:%s"
 	keyFeedQueue     string // имя списка, например "feed_queue"
+	keyCategorySet   string // шаблон "category_set:%s"
 	categoryProvider category.Provider
 }
 
 // NewFactRepository конструктор.
 func NewFactRepository(
-	client *redis.Client,
+	client redis.UniversalClient,
 	ttl time.Duration,
 	opts ...Option,
 ) *FactRepository {
 	repo := &FactRepository{
-		client:       client,
-		ttl:          ttl,
-		keyFact:      "fact:%s",
-		keyFeedQueue: "feed_queue",
+		client:         client,
+		ttl:            ttl,
+		keyFact:        "fact:%s",
+		keyFeedQueue:   "feed_queue",
+		keyCategorySet: "category_set:%s",
 	}
 	for _, o := range opts {
 		o(repo)
 	}
+	repo.keyFact = HashTagged(repo.hashTag, repo.keyFact)
+	repo.keyFeedQueue = HashTagged(repo.hashTag, repo.keyFeedQueue)
+	repo.keyCategorySet = HashTagged(repo.hashTag, repo.keyCategorySet)
 	return repo
 }
 
@@ -47,11 +55,20 @@ type Option func(*FactRepository)
 
 func WithKeyFact(pattern string) Option   { return func(r *FactRepository) { r.keyFact = pattern } }
 func WithKeyFeedQueue(name string) Option { return func(r *FactRepository) { r.keyFeedQueue = name } }
+func WithKeyCategorySet(pattern string) Option {
+	return func(r *FactRepository) { r.keyCategorySet = pattern }
+}
 func WithCategoryProvider(p category.Provider) Option {
 	return func(r *FactRepository) { r.categoryProvider = p }
 }
 
-// Save сохраняет факт и пушит его ID в очередь.
+// WithHashTag помещает все ключи репозитория в слот {tag}; применяется поверх WithKey*.
+func WithHashTag(tag string) Option { return func(r *FactRepository) { r.hashTag = tag } }
+
+// Save атомарно сохраняет факт с TTL, пушит его ID в очередь и добавляет в индекс категории.
+// Если живой факт с тем же ID уже есть, ничего не меняется и возвращается entity.ErrFactExists.
+// В очередь такой факт заново не ставится, даже если его оттуда уже сняли: очередь — это
+// ещё не показанные факты, а показанный остаётся доступен через индекс категории.
 func (r *FactRepository) Save(ctx context.Context, f *entity.Fact) error {
 	data, err := json.Marshal(f)
 	if err != nil {
@@ -59,23 +76,32 @@ func (r *FactRepository) Save(ctx context.Context, f *entity.Fact) error {
 		return fmt.Errorf("marshal fact: %w", err)
 	}
 
-	// Сохраняем JSON и ставим TTL
-	if err := r.client.Set(ctx, r.factKey(f.ID), data, r.ttl).Err(); err != nil {
-		return fmt.Errorf("redis SET: %w", err)
+	keys := []string{r.factKey(f.ID), r.keyFeedQueue, r.categoryKey(string(f.Category))}
+	saved, err := saveScript.Run(ctx, r.client, keys, data, r.ttl.Milliseconds(), string(f.ID)).Int()
+	if err != nil {
+		return fmt.Errorf("redis save script: %w", err)
 	}
-
-	// Пушим ID в начало очереди
-	if err := r.client.LPush(ctx, r.keyFeedQueue, string(f.ID)).Err(); err != nil {
-		return fmt.Errorf("redis LPUSH: %w", err)
+	if saved == 0 {
+		return entity.ErrFactExists
 	}
+	return nil
+}
 
-	// Индекс по категории
-	categoryKey := fmt.Sprintf("category_set:%s", f.Category)
-	if err := r.client.SAdd(ctx, categoryKey, string(f.ID)).Err(); err != nil {
-		logger.LoggerFromContext(ctx).Error("failed to add fact ID to category set", zap.Error(err))
-		return fmt.Errorf("redis SADD: %w", err)
+// SaveUnlisted сохраняет только ключ факта с TTL, без очереди и индекса категории.
+// Для живого факта с тем же ID возвращает entity.ErrFactExists.
+func (r *FactRepository) SaveUnlisted(ctx context.Context, f *entity.Fact) error {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("marshal fact: %w", err)
 	}
 
+	saved, err := r.client.SetNX(ctx, r.factKey(f.ID), data, r.ttl).Result()
+	if err != nil {
+		return fmt.Errorf("redis SETNX: %w", err)
+	}
+	if !saved {
+		return entity.ErrFactExists
+	}
 	return nil
 }
 
@@ -96,58 +122,72 @@ func (r *FactRepository) GetByID(ctx context.Context, id entity.FactID) (*entity
 	return &f, nil
 }
 
+// GetByIDs достаёт факты одним MGET. Истёкшие и отсутствующие ID пропускаются,
+// порядок найденных фактов совпадает с порядком ids.
+func (r *FactRepository) GetByIDs(ctx context.Context, ids []entity.FactID) ([]*entity.Fact, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = r.factKey(id)
+	}
+
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis MGET: %w", err)
+	}
+	return decodeFacts(values)
+}
+
 // GetByCategory возвращает до count фактов из множества по категории.
+// Выборка ID, чтение фактов и удаление висячих ID выполняются одним скриптом.
 func (r *FactRepository) GetByCategory(ctx context.Context, category entity.Category, count int) ([]*entity.Fact, error) {
-	categoryKey := fmt.Sprintf("category_set:%s", category)
-	ids, err := r.client.SRandMemberN(ctx, categoryKey, int64(count)).Result()
+	prefix, suffix := splitKeyPattern(r.keyFact)
+	res, err := sampleScript.Run(ctx, r.client, []string{r.categoryKey(string(category))}, count, prefix, suffix).Slice()
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch IDs for category %s: %w", category, err)
+		return nil, fmt.Errorf("failed to fetch facts for category %s: %w", category, err)
 	}
 
-	if len(ids) == 0 {
-		logger.LoggerFromContext(ctx).Info("add category to provider: " + string(category))
-		if err := r.categoryProvider.AddCategory(ctx, category); err != nil {
-			logger.LoggerFromContext(ctx).Error("failed to add category to provider", zap.Error(err))
-			return nil, err
-		}
-		return nil, entity.ErrCategoryNotFound
+	if len(res) == 0 {
+		return nil, fmt.Errorf("unexpected empty sample script result")
 	}
-
-	var facts []*entity.Fact
-	for _, idStr := range ids {
-		f, err := r.GetByID(ctx, entity.FactID(idStr))
-		if err != nil {
-			if errors.Is(err, entity.ErrFactNotFound) {
-				// удаляем "висячий" ID
-				if errRem := r.client.SRem(ctx, categoryKey, idStr).Err(); errRem != nil {
-					logger.LoggerFromContext(ctx).Error("failed to remove stale ID from category set", zap.Error(errRem))
-				}
-				continue
+	if sampled, ok := res[0].(int64); !ok || sampled == 0 {
+		if r.categoryProvider != nil {
+			logger.LoggerFromContext(ctx).Info("add category to provider: " + string(category))
+			if err := r.categoryProvider.AddCategory(ctx, category); err != nil {
+				logger.LoggerFromContext(ctx).Error("failed to add category to provider", zap.Error(err))
+				return nil, err
 			}
-			return nil, err
 		}
-		facts = append(facts, f)
+		return nil, entity.ErrCategoryNotFound
 	}
-	return facts, nil
+
+	return decodeFacts(res[1:])
 }
 
-// PopRandom берёт следующий факт из очереди (с блокирующим ожиданием до 100ms).
+// PopRandom атомарно снимает следующий ID с очереди и возвращает его факт.
+// ID истёкших фактов пропускаются; если живых фактов в очереди нет, возвращается nil, nil.
 func (r *FactRepository) PopRandom(ctx context.Context) (*entity.Fact, error) {
-	res, err := r.client.BRPop(ctx, 100*time.Millisecond, r.keyFeedQueue).Result()
+	prefix, suffix := splitKeyPattern(r.keyFact)
+	data, err := popScript.Run(ctx, r.client, []string{r.keyFeedQueue}, prefix, suffix, defaultPopAttempts).Text()
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
-			// таймаут
 			return nil, nil
 		}
-		return nil, fmt.Errorf("redis BRPOP: %w", err)
+		return nil, fmt.Errorf("redis pop script: %w", err)
 	}
-	if len(res) != 2 {
-		return nil, fmt.Errorf("unexpected BRPOP result: %v", res)
+
+	var f entity.Fact
+	if err := json.Unmarshal([]byte(data), &f); err != nil {
+		return nil, fmt.Errorf("unmarshal fact: %w", err)
 	}
-	return r.GetByID(ctx, entity.FactID(res[1]))
+	return &f, nil
 }
 
-// CountFacts возвращает длину очереди.
+// CountFacts возвращает длину очереди. Пока Sweeper не удалил ID истёкших фактов,
+// число может быть завышено.
 func (r *FactRepository) CountFacts(ctx context.Context) (int64, error) {
 	count, err := r.client.LLen(ctx, r.keyFeedQueue).Result()
 	if err != nil {
@@ -161,6 +201,30 @@ func (r *FactRepository) Ping(ctx context.Context) error {
 	return r.client.Ping(ctx).Err()
 }
 
+// decodeFacts разбирает ответы MGET/скриптов: nil означает отсутствующий ключ и пропускается.
+func decodeFacts(values []interface{}) ([]*entity.Fact, error) {
+	facts := make([]*entity.Fact, 0, len(values))
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+		data, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected fact value type: %T", v)
+		}
+		var f entity.Fact
+		if err := json.Unmarshal([]byte(data), &f); err != nil {
+			return nil, fmt.Errorf("unmarshal fact: %w", err)
+		}
+		facts = append(facts, &f)
+	}
+	return facts, nil
+}
+
 func (r *FactRepository) factKey(id entity.FactID) string {
 	return fmt.Sprintf(r.keyFact, id)
 }
+
+func (r *FactRepository) categoryKey(category string) string {
+	return fmt.Sprintf(r.keyCategorySet, category)
+}
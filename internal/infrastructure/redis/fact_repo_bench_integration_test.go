@@ -0,0 +1,65 @@
+//go:build integration_test
+
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/NordCoder/Story/internal/entity"
+	"github.com/NordCoder/Story/internal/infrastructure/facttest"
+)
+
+// Бенчмарки сравнивают загрузку выборки категории по одному ключу и одним MGET:
+//
+//	go test -tags=integration_test -run=^$ -bench=GetByID ./internal/infrastructure/redis/
+
+const benchBatchSize = 10 // столько фактов GetFact берёт из категории за раз
+
+func seedBenchFacts(b *testing.B) (*FactRepository, []entity.FactID) {
+	b.Helper()
+	ctx := context.Background()
+	client := newTestClient(b)
+	repo := newTestRepo(client, newTestTag(b, client), time.Hour)
+
+	ids := make([]entity.FactID, 0, benchBatchSize)
+	for i := 0; i < benchBatchSize; i++ {
+		f := facttest.NewFact("bench", i)
+		if err := repo.Save(ctx, f); err != nil {
+			b.Fatal(err)
+		}
+		ids = append(ids, f.ID)
+	}
+	return repo, ids
+}
+
+func BenchmarkGetByIDLoop(b *testing.B) {
+	ctx := context.Background()
+	repo, ids := seedBenchFacts(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, id := range ids {
+			if _, err := repo.GetByID(ctx, id); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkGetByIDs(b *testing.B) {
+	ctx := context.Background()
+	repo, ids := seedBenchFacts(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		facts, err := repo.GetByIDs(ctx, ids)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(facts) != len(ids) {
+			b.Fatalf("GetByIDs() returned %d facts, want %d", len(facts), len(ids))
+		}
+	}
+}
@@ -0,0 +1,18 @@
+//go:build integration_test
+
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NordCoder/Story/internal/infrastructure"
+	"github.com/NordCoder/Story/internal/infrastructure/facttest"
+)
+
+func TestFactRepositoryContract(t *testing.T) {
+	facttest.Run(t, func(t *testing.T, ttl time.Duration) (infrastructure.FactRepository, func(time.Duration)) {
+		client := newTestClient(t)
+		return newTestRepo(client, newTestTag(t, client), ttl), time.Sleep
+	})
+}
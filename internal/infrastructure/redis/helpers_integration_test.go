@@ -0,0 +1,141 @@
+//go:build integration_test
+
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/NordCoder/Story/internal/entity"
+	"github.com/go-redis/redis/v8"
+)
+
+// Интеграционные тесты идут против настоящего Redis (make integration-test).
+// Адрес берётся из REDIS_TEST_ADDR, по умолчанию localhost:6379. Ключи каждого теста живут
+// под собственным hash tag и удаляются после теста, так что общий Redis не засоряется.
+
+func testRedisAddr() string {
+	if addr := os.Getenv("REDIS_TEST_ADDR"); addr != "" {
+		return addr
+	}
+	return "localhost:6379"
+}
+
+// newTestClient подключается к тестовому Redis; opts правят настройки клиента.
+func newTestClient(tb testing.TB, opts ...func(*redis.Options)) *redis.Client {
+	tb.Helper()
+	o := &redis.Options{Addr: testRedisAddr()}
+	for _, f := range opts {
+		f(o)
+	}
+	client := redis.NewClient(o)
+	tb.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+// newTestTag возвращает уникальный hash tag и удаляет все его ключи после теста.
+func newTestTag(tb testing.TB, client *redis.Client) string {
+	tb.Helper()
+	ctx := context.Background()
+	if err := client.Ping(ctx).Err(); err != nil {
+		tb.Fatalf("redis at %s is unavailable: %v", testRedisAddr(), err)
+	}
+	tag := fmt.Sprintf("test-%d", rand.Int63())
+	tb.Cleanup(func() {
+		_ = scanKeys(ctx, client, HashTagged(tag, "*"), 500, func(key string) error {
+			return client.Del(ctx, key).Err()
+		})
+	})
+	return tag
+}
+
+// newTestRepo создаёт репозиторий над client в пространстве ключей tag.
+func newTestRepo(client redis.UniversalClient, tag string, ttl time.Duration) *FactRepository {
+	return NewFactRepository(client, ttl, WithHashTag(tag))
+}
+
+// cutConn пропускает запись команды и сразу закрывает соединение, не дожидаясь ответа:
+// клиент получает ошибку «посреди вызова», а сервер уже получил команду целиком.
+type cutConn struct {
+	net.Conn
+}
+
+func (c cutConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	_ = c.Conn.Close()
+	return n, err
+}
+
+// withCutConnections делает так, что каждый вызов клиента обрывается после отправки команды.
+// Повторы отключены, иначе клиент переотправил бы команду по новому соединению.
+func withCutConnections(o *redis.Options) {
+	o.MaxRetries = -1
+	o.Dialer = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		return cutConn{Conn: conn}, nil
+	}
+}
+
+// loadScripts заранее кладёт скрипты в кэш Redis: EVALSHA по оборванному соединению
+// не сможет откатиться на EVAL, и без этого команда не выполнилась бы вовсе.
+func loadScripts(tb testing.TB, client *redis.Client) {
+	tb.Helper()
+	for _, s := range []*redis.Script{saveScript, popScript, sampleScript, sweepListScript, sweepSetScript} {
+		if err := s.Load(context.Background(), client).Err(); err != nil {
+			tb.Fatalf("load script: %v", err)
+		}
+	}
+}
+
+// assertConsistent проверяет инварианты хранилища: каждый ID в очереди и в индексах категорий
+// указывает на живой факт, и каждый факт есть в индексе своей категории (достижим выборкой).
+func assertConsistent(tb testing.TB, client *redis.Client, repo *FactRepository) {
+	tb.Helper()
+	ctx := context.Background()
+
+	queue, err := client.LRange(ctx, repo.keyFeedQueue, 0, -1).Result()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	for _, id := range queue {
+		if n := client.Exists(ctx, repo.factKey(entity.FactID(id))).Val(); n == 0 {
+			tb.Errorf("queue holds orphan id %s", id)
+		}
+	}
+
+	err = scanKeys(ctx, client, repo.categoryKey("*"), 500, func(key string) error {
+		for _, id := range client.SMembers(ctx, key).Val() {
+			if n := client.Exists(ctx, repo.factKey(entity.FactID(id))).Val(); n == 0 {
+				tb.Errorf("%s holds orphan id %s", key, id)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	err = scanKeys(ctx, client, repo.factKey("*"), 500, func(key string) error {
+		var f entity.Fact
+		if err := json.Unmarshal([]byte(client.Get(ctx, key).Val()), &f); err != nil {
+			return err
+		}
+		if !client.SIsMember(ctx, repo.categoryKey(string(f.Category)), string(f.ID)).Val() {
+			tb.Errorf("fact %s is missing from its category index", f.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		tb.Fatal(err)
+	}
+}
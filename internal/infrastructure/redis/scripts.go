@@ -0,0 +1,127 @@
+package redis
+
+import (
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Lua-скрипты выполняются в Redis атомарно и за один round trip: ни падение процесса,
+// ни обрыв соединения не оставят факт без индексов или индексы без факта.
+//
+// Все ключи, которые скрипт трогает, передаются через KEYS, как того требует контракт
+// EVAL. Исключение — popScript и sampleScript: ID в них становится известен только после
+// RPOP/SRANDMEMBER, поэтому ключ факта строится внутри скрипта из префикса и суффикса
+// шаблона keyFact. В кластере это работает лишь потому, что ключи фактов несут тот же
+// hash tag, что и очередь с индексами (RedisConfig.Validate требует его в mode: cluster),
+// и попадают на тот же узел; без тега такой скрипт упадёт с ошибкой чужого слота.
+
+// saveScript сохраняет факт, только если его ещё нет, и добавляет ID в очередь и индекс категории.
+//
+//	KEYS[1] — ключ факта, KEYS[2] — очередь, KEYS[3] — множество категории
+//	ARGV[1] — JSON факта, ARGV[2] — TTL в миллисекундах (0 — без TTL), ARGV[3] — ID
+//
+// Возвращает 1, если факт сохранён, и 0, если факт с таким ID уже есть.
+var saveScript = redis.NewScript(`
+local ok
+if tonumber(ARGV[2]) > 0 then
+	ok = redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2], 'NX')
+else
+	ok = redis.call('SET', KEYS[1], ARGV[1], 'NX')
+end
+if not ok then
+	return 0
+end
+redis.call('LPUSH', KEYS[2], ARGV[3])
+redis.call('SADD', KEYS[3], ARGV[3])
+return 1
+`)
+
+// popScript снимает ID с конца очереди и возвращает JSON факта.
+// ID, чьи факты уже истекли, выбрасываются; проверяется не больше ARGV[3] ID за вызов.
+//
+//	KEYS[1] — очередь
+//	ARGV[1], ARGV[2] — префикс и суффикс ключа факта, ARGV[3] — лимит попыток
+//
+// Ключ факта не объявлен в KEYS (см. комментарий в начале файла).
+//
+// Возвращает nil, если очередь пуста или лимит исчерпан.
+var popScript = redis.NewScript(`
+for _ = 1, tonumber(ARGV[3]) do
+	local id = redis.call('RPOP', KEYS[1])
+	if not id then
+		return false
+	end
+	local data = redis.call('GET', ARGV[1] .. id .. ARGV[2])
+	if data then
+		return data
+	end
+end
+return false
+`)
+
+// sampleScript берёт до ARGV[1] случайных ID из множества категории и возвращает их факты;
+// ID истёкших фактов удаляются из множества в том же вызове.
+//
+//	KEYS[1] — множество категории
+//	ARGV[1] — сколько ID выбрать, ARGV[2], ARGV[3] — префикс и суффикс ключа факта
+//
+// Ключи фактов не объявлены в KEYS (см. комментарий в начале файла).
+// Возвращает массив: первым элементом — сколько ID было выбрано, далее — JSON найденных фактов.
+var sampleScript = redis.NewScript(`
+local ids = redis.call('SRANDMEMBER', KEYS[1], ARGV[1])
+local out = {#ids}
+for _, id in ipairs(ids) do
+	local data = redis.call('GET', ARGV[2] .. id .. ARGV[3])
+	if data then
+		table.insert(out, data)
+	else
+		redis.call('SREM', KEYS[1], id)
+	end
+end
+return out
+`)
+
+// sweepListScript удаляет из списка ID, чьих фактов нет. Проверка и удаление идут в одном
+// вызове: факт, пересохранённый под тем же ID между ними, не потеряет свою запись в очереди.
+//
+//	KEYS[1] — список, KEYS[2..] — ключи фактов
+//	ARGV[i] — ID факта из KEYS[i+1]
+//
+// Возвращает число удалённых элементов списка.
+var sweepListScript = redis.NewScript(`
+local removed = 0
+for i = 1, #ARGV do
+	if redis.call('EXISTS', KEYS[i + 1]) == 0 then
+		removed = removed + redis.call('LREM', KEYS[1], 0, ARGV[i])
+	end
+end
+return removed
+`)
+
+// sweepSetScript — то же для множества категории.
+//
+//	KEYS[1] — множество категории, KEYS[2..] — ключи фактов
+//	ARGV[i] — ID факта из KEYS[i+1]
+//
+// Возвращает число удалённых ID.
+var sweepSetScript = redis.NewScript(`
+local removed = 0
+for i = 1, #ARGV do
+	if redis.call('EXISTS', KEYS[i + 1]) == 0 then
+		removed = removed + redis.call('SREM', KEYS[1], ARGV[i])
+	end
+end
+return removed
+`)
+
+// defaultPopAttempts ограничивает, сколько висячих ID popScript выбросит за один вызов.
+const defaultPopAttempts = 16
+
+// splitKeyPattern разбивает шаблон вида "fact:%s" на префикс и суффикс.
+func splitKeyPattern(pattern string) (prefix, suffix string) {
+	if i := strings.Index(pattern, "%s"); i >= 0 {
+		return pattern[:i], pattern[i+2:]
+	}
+	return pattern, ""
+}
@@ -0,0 +1,210 @@
+//go:build integration_test
+
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NordCoder/Story/internal/entity"
+	"github.com/NordCoder/Story/internal/infrastructure/facttest"
+)
+
+func TestSaveLeavesNoPartialStateWhenConnectionDrops(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+	tag := newTestTag(t, client)
+	loadScripts(t, client)
+
+	cut := newTestClient(t, withCutConnections)
+	cutRepo := newTestRepo(cut, tag, time.Hour)
+	repo := newTestRepo(client, tag, time.Hour)
+
+	for i := 0; i < 50; i++ {
+		if err := cutRepo.Save(ctx, facttest.NewFact("cut", i)); err == nil {
+			t.Fatalf("save %d: expected an error from the dropped connection", i)
+		}
+	}
+	assertConsistent(t, client, repo)
+
+	// сохранённые факты либо целиком в очереди и индексе, либо отсутствуют
+	queued := client.LLen(ctx, repo.keyFeedQueue).Val()
+	indexed := client.SCard(ctx, repo.categoryKey("cut")).Val()
+	if queued != indexed {
+		t.Errorf("queue has %d ids, category index has %d", queued, indexed)
+	}
+}
+
+func TestPopRandomLeavesNoPartialStateWhenConnectionDrops(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+	tag := newTestTag(t, client)
+	loadScripts(t, client)
+
+	repo := newTestRepo(client, tag, time.Hour)
+	for i := 0; i < 20; i++ {
+		if err := repo.Save(ctx, facttest.NewFact("pop", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cutRepo := newTestRepo(newTestClient(t, withCutConnections), tag, time.Hour)
+	for i := 0; i < 10; i++ {
+		if _, err := cutRepo.PopRandom(ctx); err == nil {
+			t.Fatalf("pop %d: expected an error from the dropped connection", i)
+		}
+	}
+	assertConsistent(t, client, repo)
+
+	// снятые с очереди факты остаются достижимыми через индекс категории
+	facts, err := repo.GetByCategory(ctx, "pop", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(facts) != 20 {
+		t.Errorf("category sample returned %d facts, want 20", len(facts))
+	}
+}
+
+func TestPopRandomDropsOrphanQueueEntries(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+	tag := newTestTag(t, client)
+	repo := newTestRepo(client, tag, time.Hour)
+
+	live := facttest.NewFact("orphans", 0)
+	if err := repo.Save(ctx, live); err != nil {
+		t.Fatal(err)
+	}
+	// полузаписанное состояние: ID в хвосте очереди, но ключей фактов нет
+	for i := 0; i < 3; i++ {
+		client.RPush(ctx, repo.keyFeedQueue, string(entity.NewFactID()))
+	}
+
+	got, err := repo.PopRandom(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || got.ID != live.ID {
+		t.Fatalf("PopRandom() = %v, want the live fact %s", got, live.ID)
+	}
+	if n := client.LLen(ctx, repo.keyFeedQueue).Val(); n != 0 {
+		t.Errorf("queue still has %d entries", n)
+	}
+	assertConsistent(t, client, repo)
+}
+
+func TestPopRandomBoundsOrphanScan(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+	tag := newTestTag(t, client)
+	repo := newTestRepo(client, tag, time.Hour)
+
+	for i := 0; i < defaultPopAttempts+5; i++ {
+		client.RPush(ctx, repo.keyFeedQueue, string(entity.NewFactID()))
+	}
+
+	got, err := repo.PopRandom(ctx)
+	if err != nil || got != nil {
+		t.Fatalf("PopRandom() = %v, %v; want nil, nil", got, err)
+	}
+	if n := client.LLen(ctx, repo.keyFeedQueue).Val(); n != 5 {
+		t.Errorf("queue has %d entries, want 5 left after one bounded scan", n)
+	}
+}
+
+func TestSampleDropsOrphanCategoryEntries(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+	tag := newTestTag(t, client)
+	repo := newTestRepo(client, tag, time.Hour)
+
+	for i := 0; i < 2; i++ {
+		if err := repo.Save(ctx, facttest.NewFact("sample", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 5; i++ {
+		client.SAdd(ctx, repo.categoryKey("sample"), string(entity.NewFactID()))
+	}
+
+	facts, err := repo.GetByCategory(ctx, "sample", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(facts) != 2 {
+		t.Errorf("GetByCategory() returned %d facts, want 2", len(facts))
+	}
+	assertConsistent(t, client, repo)
+}
+
+func TestSampleAfterExpiryLeavesNoOrphans(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+	tag := newTestTag(t, client)
+	repo := newTestRepo(client, tag, 50*time.Millisecond)
+
+	if err := repo.Save(ctx, facttest.NewFact("expiring", 0)); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	if _, err := repo.GetByCategory(ctx, "expiring", 10); err != nil {
+		t.Fatal(err)
+	}
+	if n := client.SCard(ctx, repo.categoryKey("expiring")).Val(); n != 0 {
+		t.Errorf("category index has %d entries after its only fact expired", n)
+	}
+}
+
+func TestSaveDoesNotDuplicateIndexEntries(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+	tag := newTestTag(t, client)
+	repo := newTestRepo(client, tag, time.Hour)
+
+	f := facttest.NewFact("dup", 0)
+	if err := repo.Save(ctx, f); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := repo.Save(ctx, f); !errors.Is(err, entity.ErrFactExists) {
+			t.Fatalf("repeated Save() error = %v, want ErrFactExists", err)
+		}
+	}
+	if n := client.LLen(ctx, repo.keyFeedQueue).Val(); n != 1 {
+		t.Errorf("queue has %d entries, want 1", n)
+	}
+	if n := client.SCard(ctx, repo.categoryKey("dup")).Val(); n != 1 {
+		t.Errorf("category index has %d entries, want 1", n)
+	}
+}
+
+func TestSweepKeepsIDsOfResavedFacts(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+	tag := newTestTag(t, client)
+	repo := newTestRepo(client, tag, time.Hour)
+
+	f := facttest.NewFact("sweep", 0)
+	if err := repo.Save(ctx, f); err != nil {
+		t.Fatal(err)
+	}
+	orphan := string(entity.NewFactID())
+	client.RPush(ctx, repo.keyFeedQueue, orphan)
+	client.SAdd(ctx, repo.categoryKey("sweep"), orphan)
+
+	report, err := repo.Sweep(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.QueueRemoved != 1 || report.CategoryRemoved != 1 {
+		t.Errorf("Sweep() = %+v, want one orphan removed from each index", report)
+	}
+	if got, err := repo.PopRandom(ctx); err != nil || got == nil || got.ID != f.ID {
+		t.Errorf("live fact lost by sweep: %v, %v", got, err)
+	}
+	assertConsistent(t, client, repo)
+}
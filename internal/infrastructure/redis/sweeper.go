@@ -0,0 +1,191 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/NordCoder/Story/internal/entity"
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// SweepReport — сколько «висячих» ID (без живого ключа факта) удалено за проход.
+type SweepReport struct {
+	QueueRemoved    int
+	CategoryRemoved int
+	CategorySets    int
+	Duration        time.Duration
+}
+
+// Sweep удаляет из очереди и индексов категорий ID фактов, чьи ключи уже истекли по TTL.
+// Обход идёт порциями по batch ID, так что Redis не блокируется на больших списках.
+func (r *FactRepository) Sweep(ctx context.Context, batch int) (SweepReport, error) {
+	start := time.Now()
+	var report SweepReport
+
+	removed, err := r.sweepQueue(ctx, batch)
+	report.QueueRemoved = removed
+	if err != nil {
+		return report, err
+	}
+
+	err = scanKeys(ctx, r.client, r.categoryKey("*"), int64(batch), func(key string) error {
+		removed, err := r.sweepCategorySet(ctx, key, batch)
+		report.CategoryRemoved += removed
+		report.CategorySets++
+		return err
+	})
+	if err != nil {
+		return report, err
+	}
+
+	report.Duration = time.Since(start)
+	return report, nil
+}
+
+func (r *FactRepository) sweepQueue(ctx context.Context, batch int) (int, error) {
+	removed := 0
+	for start := int64(0); ; start += int64(batch) {
+		ids, err := r.client.LRange(ctx, r.keyFeedQueue, start, start+int64(batch)-1).Result()
+		if err != nil {
+			return removed, fmt.Errorf("redis LRANGE: %w", err)
+		}
+		if len(ids) == 0 {
+			return removed, nil
+		}
+
+		n, err := r.removeDangling(ctx, sweepListScript, r.keyFeedQueue, ids)
+		if err != nil {
+			return removed, err
+		}
+		removed += n
+		// удалённые элементы сдвигают индексы, поэтому следующая порция начинается раньше
+		start -= int64(n)
+	}
+}
+
+func (r *FactRepository) sweepCategorySet(ctx context.Context, key string, batch int) (int, error) {
+	removed := 0
+	iter := r.client.SScan(ctx, key, 0, "", int64(batch)).Iterator()
+	ids := make([]string, 0, batch)
+
+	flush := func() error {
+		n, err := r.removeDangling(ctx, sweepSetScript, key, ids)
+		removed += n
+		ids = ids[:0]
+		return err
+	}
+
+	for iter.Next(ctx) {
+		ids = append(ids, iter.Val())
+		if len(ids) >= batch {
+			if err := flush(); err != nil {
+				return removed, err
+			}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return removed, fmt.Errorf("redis SSCAN: %w", err)
+	}
+	if len(ids) > 0 {
+		if err := flush(); err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
+
+// removeDangling удаляет из индекса key те ids, чьих фактов нет. Проверка EXISTS и удаление
+// выполняются скриптом атомарно: иначе факт, заново сохранённый под тем же ID
+// (например, прогрев из архива), потерял бы только что добавленную запись в индексе.
+func (r *FactRepository) removeDangling(ctx context.Context, script *redis.Script, key string, ids []string) (int, error) {
+	keys := make([]string, 0, len(ids)+1)
+	keys = append(keys, key)
+	args := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, r.factKey(entity.FactID(id)))
+		args = append(args, id)
+	}
+	n, err := script.Run(ctx, r.client, keys, args...).Int()
+	if err != nil {
+		return 0, fmt.Errorf("redis sweep script: %w", err)
+	}
+	return n, nil
+}
+
+// Sweeper периодически запускает FactRepository.Sweep.
+type Sweeper struct {
+	repo     *FactRepository
+	interval time.Duration
+	batch    int
+	logger   *zap.Logger
+	removed  *prometheus.CounterVec
+}
+
+type SweeperOption func(*Sweeper)
+
+// WithSweeperMetrics регистрирует счётчик удалённых висячих ID.
+func WithSweeperMetrics(reg prometheus.Registerer) SweeperOption {
+	return func(s *Sweeper) {
+		s.removed = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "wikifeed",
+				Subsystem: "redis",
+				Name:      "dangling_ids_removed_total",
+				Help:      "Fact IDs removed from queue and category indexes after their fact expired",
+			},
+			[]string{"index"},
+		)
+		reg.MustRegister(s.removed)
+	}
+}
+
+const defaultSweepBatch = 500
+
+func NewSweeper(repo *FactRepository, interval time.Duration, batch int, logger *zap.Logger, opts ...SweeperOption) *Sweeper {
+	if batch <= 0 {
+		batch = defaultSweepBatch
+	}
+	s := &Sweeper{
+		repo:     repo,
+		interval: interval,
+		batch:    batch,
+		logger:   logger,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Run блокируется до отмены ctx.
+func (s *Sweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Redis sweeper stopping gracefully")
+			return
+		case <-ticker.C:
+			report, err := s.repo.Sweep(ctx, s.batch)
+			if s.removed != nil {
+				s.removed.WithLabelValues("feed_queue").Add(float64(report.QueueRemoved))
+				s.removed.WithLabelValues("category_set").Add(float64(report.CategoryRemoved))
+			}
+			if err != nil {
+				s.logger.Error("Redis sweep failed", zap.Error(err))
+				continue
+			}
+			s.logger.Info("Redis sweep finished",
+				zap.Int("queue_removed", report.QueueRemoved),
+				zap.Int("category_removed", report.CategoryRemoved),
+				zap.Int("category_sets", report.CategorySets),
+				zap.Duration("duration", report.Duration),
+			)
+		}
+	}
+}
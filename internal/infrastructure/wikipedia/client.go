@@ -44,6 +44,7 @@ func (a *ArticleSummary) ToFact(category entity.Category) *entity.Fact {
 		Summary:   trimSummary(a.Extract), // обрезаем до 280 символов
 		ImageURL:  a.ImageURL,
 		SourceURL: a.PageURL,
+		Lang:      a.Lang,
 		FetchedAt: time.Now(), // ставим текущее время
 	}
 }
@@ -65,6 +66,7 @@ type HTTPClient interface {
 type Client struct {
 	httpClient  HTTPClient
 	apiURL      string
+	lang        string // derived from apiURL host, e.g. "ru" for ru.wikipedia.org
 	userAgent   string
 	pageSize    int
 	maxLag      int
@@ -142,9 +144,24 @@ func NewClient(opts ...Option) WikiClient {
 	for _, opt := range opts {
 		opt(client)
 	}
+	client.lang = langFromAPIURL(client.apiURL)
 	return client
 }
 
+// langFromAPIURL extracts the language subdomain of a Wikipedia API URL;
+// empty if the host is not <lang>.wikipedia.org.
+func langFromAPIURL(apiURL string) string {
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return ""
+	}
+	lang, rest, ok := strings.Cut(u.Hostname(), ".")
+	if !ok || rest != "wikipedia.org" {
+		return ""
+	}
+	return lang
+}
+
 // doRequest executes a GET to the API with retry, metrics, and structured logging
 func (c *Client) doRequest(ctx context.Context, params url.Values, out interface{}) error {
 	// Add common params
@@ -249,30 +266,74 @@ func (c *Client) GetCategorySummaries(ctx context.Context, category entity.Categ
 		"pithumbsize":  {fmt.Sprint(1500)},
 	}
 
-	var resp struct {
-		Query struct {
-			Pages map[string]struct {
-				Title        string     `json:"title"`
-				Extract      string     `json:"extract"`
-				Thumbnail    *Thumbnail `json:"thumbnail,omitempty"`
-				CanonicalURL string     `json:"canonicalurl"`
-			} `json:"pages"`
-		} `json:"query"`
-		Error *struct{ Code, Info string } `json:"error,omitempty"`
+	var resp summaryResponse
+	if err := c.doRequest(ctx, params, &resp); err != nil {
+		return nil, err
+	}
+	summaries, err := resp.summaries(category, c.lang)
+	if err != nil {
+		return nil, err
+	}
+
+	c.logger.Info("fetched category summaries", zap.String("category", string(category)), zap.Int("count", len(summaries)))
+	return summaries, nil
+}
+
+// GetLinkedSummaries retrieves up to limit summaries of articles linked from the given article
+// via generator=links. Summaries keep the category of the source article.
+func (c *Client) GetLinkedSummaries(ctx context.Context, title string, category entity.Category, limit int) ([]*ArticleSummary, error) {
+	params := url.Values{
+		"action":       {"query"},
+		"generator":    {"links"},
+		"titles":       {title},
+		"gplnamespace": {"0"},
+		"gpllimit":     {fmt.Sprint(limit)},
+		"prop":         {"extracts|pageimages|info"},
+		"inprop":       {"url"},
+		"exintro":      {"true"},
+		"explaintext":  {"true"},
+		"exlimit":      {"max"},
+		"piprop":       {"thumbnail"},
+		"pithumbsize":  {fmt.Sprint(1500)},
 	}
 
+	var resp summaryResponse
 	if err := c.doRequest(ctx, params, &resp); err != nil {
 		return nil, err
 	}
-	if resp.Error != nil {
-		return nil, fmt.Errorf("wikiapi error %s: %s", resp.Error.Code, resp.Error.Info)
+	summaries, err := resp.summaries(category, c.lang)
+	if err != nil {
+		return nil, err
 	}
-	if len(resp.Query.Pages) == 0 {
+
+	c.logger.Info("fetched linked summaries", zap.String("title", title), zap.Int("count", len(summaries)))
+	return summaries, nil
+}
+
+// summaryResponse is the shape of generator queries with prop=extracts|pageimages|info.
+type summaryResponse struct {
+	Query struct {
+		Pages map[string]struct {
+			Title        string     `json:"title"`
+			Extract      string     `json:"extract"`
+			Thumbnail    *Thumbnail `json:"thumbnail,omitempty"`
+			CanonicalURL string     `json:"canonicalurl"`
+		} `json:"pages"`
+	} `json:"query"`
+	Error *struct{ Code, Info string } `json:"error,omitempty"`
+}
+
+// summaries converts valid pages of the response to summaries of the given category.
+func (r *summaryResponse) summaries(category entity.Category, lang string) ([]*ArticleSummary, error) {
+	if r.Error != nil {
+		return nil, fmt.Errorf("wikiapi error %s: %s", r.Error.Code, r.Error.Info)
+	}
+	if len(r.Query.Pages) == 0 {
 		return nil, ErrNoPages
 	}
 
-	summaries := make([]*ArticleSummary, 0, len(resp.Query.Pages))
-	for _, p := range resp.Query.Pages {
+	summaries := make([]*ArticleSummary, 0, len(r.Query.Pages))
+	for _, p := range r.Query.Pages {
 		if !isValidArticle(p.Title, p.Extract, p.Thumbnail) {
 			continue
 		}
@@ -291,10 +352,9 @@ func (c *Client) GetCategorySummaries(ctx context.Context, category entity.Categ
 			Extract:  p.Extract,
 			ImageURL: imageURL,
 			PageURL:  pageURL,
+			Lang:     lang,
 		})
 	}
-
-	c.logger.Info("fetched category summaries", zap.String("category", string(category)), zap.Int("count", len(summaries)))
 	return summaries, nil
 }
 
@@ -332,8 +392,52 @@ func (c *Client) GetSubcategories(ctx context.Context, category entity.Category,
 
 	names := make([]entity.Category, len(resp.Query.CategoryMembers))
 	for i, m := range resp.Query.CategoryMembers {
-		// strip the "Category:" prefix
-		names[i] = entity.Category(strings.TrimPrefix(m.Title, "Category:"))
+		names[i] = categoryFromTitle(m.Title)
 	}
 	return names, nil
 }
+
+// GetParentCategories retrieves up to limit visible categories the given category belongs to.
+func (c *Client) GetParentCategories(ctx context.Context, category entity.Category, limit int) ([]entity.Category, error) {
+	params := url.Values{
+		"action":  {"query"},
+		"titles":  {"Category:" + string(category)},
+		"prop":    {"categories"},
+		"clshow":  {"!hidden"},
+		"cllimit": {fmt.Sprint(limit)},
+	}
+
+	var resp struct {
+		Query struct {
+			Pages map[string]struct {
+				Categories []struct {
+					Title string `json:"title"`
+				} `json:"categories"`
+			} `json:"pages"`
+		} `json:"query"`
+		Error *struct{ Code, Info string } `json:"error,omitempty"`
+	}
+
+	if err := c.doRequest(ctx, params, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, fmt.Errorf("wikiapi error %s: %s", resp.Error.Code, resp.Error.Info)
+	}
+
+	var names []entity.Category
+	for _, p := range resp.Query.Pages {
+		for _, m := range p.Categories {
+			names = append(names, categoryFromTitle(m.Title))
+		}
+	}
+	return names, nil
+}
+
+// categoryFromTitle strips the namespace prefix ("Category:" or localized "Категория:")
+// and converts the title to the underscore form used in entity.Category.
+func categoryFromTitle(title string) entity.Category {
+	title = strings.TrimPrefix(title, "Category:")
+	title = strings.TrimPrefix(title, "Категория:")
+	return entity.Category(strings.ReplaceAll(title, " ", "_"))
+}
@@ -10,6 +10,8 @@ import (
 type WikiClient interface {
 	GetCategorySummaries(ctx context.Context, category entity.Category, limit int) ([]*ArticleSummary, error)
 	GetSubcategories(ctx context.Context, category entity.Category, limit int) ([]entity.Category, error)
+	GetParentCategories(ctx context.Context, category entity.Category, limit int) ([]entity.Category, error)
+	GetLinkedSummaries(ctx context.Context, title string, category entity.Category, limit int) ([]*ArticleSummary, error)
 	Ping(ctx context.Context) error
 }
 
@@ -23,4 +25,5 @@ type ArticleSummary struct {
 	Extract  string
 	ImageURL string
 	PageURL  string
+	Lang     string // language code of the wiki, e.g. "ru"
 }
@@ -13,6 +13,25 @@ func (w *wikiMock) GetSubcategories(ctx context.Context, category entity.Categor
 	panic("implement me")
 }
 
+func (w *wikiMock) GetParentCategories(ctx context.Context, category entity.Category, limit int) ([]entity.Category, error) {
+	return nil, nil
+}
+
+// GetLinkedSummaries в заглушке возвращает другие статьи той же категории.
+func (w *wikiMock) GetLinkedSummaries(ctx context.Context, title string, category entity.Category, limit int) ([]*ArticleSummary, error) {
+	items, err := w.GetCategorySummaries(ctx, category, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	linked := make([]*ArticleSummary, 0, len(items))
+	for _, it := range items {
+		if it.Title != title && len(linked) < limit {
+			linked = append(linked, it)
+		}
+	}
+	return linked, nil
+}
+
 // NewWikiMock создаёт новый экземпляр заглушки
 func NewWikiMock() WikiClient {
 	return &wikiMock{}
@@ -116,6 +135,9 @@ func (w *wikiMock) GetCategorySummaries(ctx context.Context, category entity.Cat
 	if limit > 0 && limit < len(items) {
 		items = items[:limit]
 	}
+	for _, it := range items {
+		it.Lang = "en"
+	}
 	return items, nil
 }
 
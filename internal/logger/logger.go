@@ -16,10 +16,17 @@ type ctxLoggerKey struct{}
 
 var Key = ctxLoggerKey{}
 
+// atomicLevel — минимальный уровень всех ядер; меняется на лету через SetLevel.
+var atomicLevel = zap.NewAtomicLevelAt(zapcore.InfoLevel)
+
+// SetLevel меняет уровень логирования без пересоздания логгера.
+func SetLevel(level string) error {
+	return atomicLevel.UnmarshalText([]byte(level))
+}
+
 func Init(config *config.LoggerConfig) (*zap.Logger, error) {
-	var level zapcore.Level
-	if err := level.UnmarshalText([]byte(config.Level)); err != nil {
-		level = zapcore.InfoLevel
+	if err := SetLevel(config.Level); err != nil {
+		atomicLevel.SetLevel(zapcore.InfoLevel)
 	}
 
 	encoderCfg := zap.NewProductionEncoderConfig()
@@ -36,13 +43,13 @@ func Init(config *config.LoggerConfig) (*zap.Logger, error) {
 
 	for _, path := range config.OutputPaths {
 		cores = append(cores, newCore(config, path, encoder, func(l zapcore.Level) bool {
-			return l < zapcore.ErrorLevel
+			return atomicLevel.Enabled(l) && l < zapcore.ErrorLevel
 		}))
 	}
 
 	for _, path := range config.ErrorOutputPaths {
 		cores = append(cores, newCore(config, path, encoder, func(l zapcore.Level) bool {
-			return l >= zapcore.ErrorLevel
+			return atomicLevel.Enabled(l) && l >= zapcore.ErrorLevel
 		}))
 	}
 
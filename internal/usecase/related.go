@@ -0,0 +1,235 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+
+	"github.com/NordCoder/Story/config"
+	"github.com/NordCoder/Story/internal/entity"
+	"github.com/NordCoder/Story/internal/infrastructure/wikipedia"
+	"github.com/NordCoder/Story/internal/logger"
+	"go.uber.org/zap"
+)
+
+var ErrRelatedDisabled = errors.New("related facts are disabled")
+
+// Relation — чем похожий факт связан с исходным.
+type Relation string
+
+const (
+	RelationSameCategory    Relation = "same_category"    // та же категория
+	RelationSiblingCategory Relation = "sibling_category" // соседняя подкатегория общего родителя
+	RelationLinked          Relation = "linked"           // статья, на которую ссылается исходная
+)
+
+type RelatedFact struct {
+	Fact     entity.Fact
+	Relation Relation
+}
+
+type GetRelatedFactsInput struct {
+	FactID entity.FactID
+	Limit  int // 0 — related.default_limit
+}
+
+type GetRelatedFactsOutput struct {
+	Facts []RelatedFact
+	// Partial — бюджет на Википедию истёк раньше, чем ответили все источники.
+	Partial bool
+}
+
+// LinkedFetcher загружает по запросу статьи, на которые ссылается статья факта.
+type LinkedFetcher interface {
+	FetchLinked(ctx context.Context, source *entity.Fact, limit int) ([]*entity.Fact, error)
+}
+
+type relatedDeps struct {
+	wiki    wikipedia.WikiClient
+	fetcher LinkedFetcher
+	cfg     config.RelatedConfig
+}
+
+// WithRelated включает GetRelatedFacts: соседние подкатегории ищутся через wiki,
+// а недостающие факты догружаются fetcher'ом.
+func WithRelated(wiki wikipedia.WikiClient, fetcher LinkedFetcher, cfg config.RelatedConfig) Option {
+	return func(uc *FactUseCaseImpl) {
+		uc.related = &relatedDeps{wiki: wiki, fetcher: fetcher, cfg: cfg}
+	}
+}
+
+// GetRelatedFacts подбирает «похожие» факты. Сначала берутся факты из инвентаря: той же категории
+// и соседних подкатегорий (родители категории и их подкатегории узнаются у Википедии). Если их
+// не хватает, догружаются статьи, на которые ссылается исходная. Всё, что ждёт Википедию,
+// укладывается в related.budget: не успевшие источники пропускаются.
+func (uc *FactUseCaseImpl) GetRelatedFacts(ctx context.Context, input GetRelatedFactsInput) (GetRelatedFactsOutput, error) {
+	if uc.related == nil {
+		return GetRelatedFactsOutput{}, ErrRelatedDisabled
+	}
+	cfg := uc.related.cfg
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = cfg.DefaultLimit
+	}
+	if limit > cfg.MaxLimit {
+		limit = cfg.MaxLimit
+	}
+
+	source, err := uc.factRepo.GetByID(ctx, input.FactID)
+	if err != nil {
+		return GetRelatedFactsOutput{}, err
+	}
+
+	budgetCtx, cancel := context.WithTimeout(ctx, cfg.Budget)
+	defer cancel()
+
+	var same, siblings []*entity.Fact
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		// +1: в выборку может попасть сам исходный факт
+		same = uc.inventory(budgetCtx, source.Category, cfg.PerCategory+1)
+	}()
+	go func() {
+		defer wg.Done()
+		siblings = uc.siblingFacts(budgetCtx, source.Category)
+	}()
+	wg.Wait()
+
+	set := newRelatedSet(source, limit)
+	set.interleave(same, RelationSameCategory, siblings, RelationSiblingCategory)
+
+	if !set.full() && uc.related.fetcher != nil && budgetCtx.Err() == nil {
+		linked, err := uc.related.fetcher.FetchLinked(budgetCtx, source, limit-len(set.facts))
+		if err != nil && budgetCtx.Err() == nil {
+			logger.LoggerFromContext(ctx).Warn("GetRelatedFacts: failed to fetch linked articles", zap.Error(err))
+		}
+		set.add(linked, RelationLinked)
+	}
+
+	return GetRelatedFactsOutput{Facts: set.facts, Partial: budgetCtx.Err() != nil}, nil
+}
+
+// inventory возвращает до count фактов категории из репозитория; пустая категория — не ошибка.
+func (uc *FactUseCaseImpl) inventory(ctx context.Context, category entity.Category, count int) []*entity.Fact {
+	facts, err := uc.factRepo.GetByCategory(ctx, category, count)
+	if err != nil && !errors.Is(err, entity.ErrCategoryNotFound) && ctx.Err() == nil {
+		logger.LoggerFromContext(ctx).Warn("GetRelatedFacts: failed to read category", zap.String("category", string(category)), zap.Error(err))
+	}
+	return facts
+}
+
+// siblingFacts собирает факты из инвентаря по соседним подкатегориям: подкатегориям родителей
+// category, кроме неё самой. Просматривается не больше related.siblings случайных соседей.
+func (uc *FactUseCaseImpl) siblingFacts(ctx context.Context, category entity.Category) []*entity.Fact {
+	cfg := uc.related.cfg
+	if uc.related.wiki == nil || cfg.ParentCategories == 0 || cfg.Siblings == 0 {
+		return nil
+	}
+
+	parents, err := uc.related.wiki.GetParentCategories(ctx, category, cfg.ParentCategories)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.LoggerFromContext(ctx).Warn("GetRelatedFacts: failed to get parent categories", zap.String("category", string(category)), zap.Error(err))
+		}
+		return nil
+	}
+
+	var mu sync.Mutex
+	seen := map[entity.Category]struct{}{category: {}}
+	var siblings []entity.Category
+
+	var wg sync.WaitGroup
+	for _, parent := range parents {
+		wg.Add(1)
+		go func(parent entity.Category) {
+			defer wg.Done()
+			subs, err := uc.related.wiki.GetSubcategories(ctx, parent, cfg.Siblings)
+			if err != nil {
+				if ctx.Err() == nil {
+					logger.LoggerFromContext(ctx).Warn("GetRelatedFacts: failed to get subcategories", zap.String("category", string(parent)), zap.Error(err))
+				}
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for _, sub := range subs {
+				if _, ok := seen[sub]; !ok {
+					seen[sub] = struct{}{}
+					siblings = append(siblings, sub)
+				}
+			}
+		}(parent)
+	}
+	wg.Wait()
+
+	rand.Shuffle(len(siblings), func(i, j int) { siblings[i], siblings[j] = siblings[j], siblings[i] })
+	if len(siblings) > cfg.Siblings {
+		siblings = siblings[:cfg.Siblings]
+	}
+
+	var facts []*entity.Fact
+	for _, sibling := range siblings {
+		if ctx.Err() != nil {
+			break
+		}
+		facts = append(facts, uc.inventory(ctx, sibling, cfg.PerCategory)...)
+	}
+	return facts
+}
+
+// relatedSet накапливает до limit похожих фактов без повторов и без исходного факта.
+// Повтором считается и факт с тем же заголовком: одна статья могла быть загружена несколько раз.
+type relatedSet struct {
+	limit  int
+	facts  []RelatedFact
+	ids    map[entity.FactID]struct{}
+	titles map[string]struct{}
+}
+
+func newRelatedSet(source *entity.Fact, limit int) *relatedSet {
+	return &relatedSet{
+		limit:  limit,
+		facts:  make([]RelatedFact, 0, limit),
+		ids:    map[entity.FactID]struct{}{source.ID: {}},
+		titles: map[string]struct{}{source.Title: {}},
+	}
+}
+
+func (s *relatedSet) full() bool { return len(s.facts) >= s.limit }
+
+func (s *relatedSet) push(f *entity.Fact, relation Relation) {
+	if s.full() {
+		return
+	}
+	if _, ok := s.ids[f.ID]; ok {
+		return
+	}
+	if _, ok := s.titles[f.Title]; ok {
+		return
+	}
+	s.ids[f.ID] = struct{}{}
+	s.titles[f.Title] = struct{}{}
+	s.facts = append(s.facts, RelatedFact{Fact: *f, Relation: relation})
+}
+
+func (s *relatedSet) add(facts []*entity.Fact, relation Relation) {
+	for _, f := range facts {
+		s.push(f, relation)
+	}
+}
+
+// interleave добавляет факты двух источников через один, чтобы ни один не занял всю выдачу.
+func (s *relatedSet) interleave(a []*entity.Fact, ra Relation, b []*entity.Fact, rb Relation) {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		if i < len(a) {
+			s.push(a[i], ra)
+		}
+		if i < len(b) {
+			s.push(b[i], rb)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/NordCoder/Story/internal/entity"
+	"github.com/NordCoder/Story/internal/infrastructure"
+	"github.com/NordCoder/Story/internal/logger"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrSearchDisabled = errors.New("fact search is disabled")
+	ErrEmptyQuery     = errors.New("search query is empty")
+)
+
+// maxSearchLimit ограничивает размер страницы поиска.
+const maxSearchLimit = 50
+
+type SearchFactsInput struct {
+	Query    string
+	Category entity.Category
+	Lang     string
+	// SeenBy — ID пользователя, если искать нужно только среди показанных ему фактов.
+	SeenBy string
+	Limit  int
+	Offset int
+}
+
+type SearchFactsOutput struct {
+	Hits  []entity.FactHit
+	Total int
+}
+
+// WithSearch включает SearchFacts поверх полнотекстового индекса.
+func WithSearch(s infrastructure.FactSearcher) Option {
+	return func(uc *FactUseCaseImpl) { uc.search = s }
+}
+
+// SearchFacts ищет факты по заголовку и тексту с фильтрами по категории и языку.
+func (uc *FactUseCaseImpl) SearchFacts(ctx context.Context, input SearchFactsInput) (SearchFactsOutput, error) {
+	if uc.search == nil {
+		return SearchFactsOutput{}, ErrSearchDisabled
+	}
+	text := strings.TrimSpace(input.Query)
+	if text == "" {
+		return SearchFactsOutput{}, ErrEmptyQuery
+	}
+	limit := input.Limit
+	if limit <= 0 || limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	hits, total, err := uc.search.Search(ctx, entity.FactQuery{
+		Text:     text,
+		Category: input.Category,
+		Lang:     input.Lang,
+		SeenBy:   input.SeenBy,
+		Limit:    limit,
+		Offset:   input.Offset,
+	})
+	if err != nil {
+		logger.LoggerFromContext(ctx).Error("SearchFacts: search failed", zap.Error(err))
+		return SearchFactsOutput{}, err
+	}
+	return SearchFactsOutput{Hits: hits, Total: total}, nil
+}
@@ -5,10 +5,15 @@ import (
 	"errors"
 	"math/rand"
 
+	"github.com/NordCoder/Story/config"
 	"github.com/NordCoder/Story/internal/entity"
+	"github.com/NordCoder/Story/internal/experiment"
 	"github.com/NordCoder/Story/internal/infrastructure"
 	"github.com/NordCoder/Story/internal/logger"
+	"github.com/NordCoder/Story/internal/weighted"
+	auth "github.com/NordCoder/Story/services/authorization/transport/http"
 	"github.com/NordCoder/Story/services/recommendation/controller"
+	recentity "github.com/NordCoder/Story/services/recommendation/entity"
 	"go.uber.org/zap"
 )
 
@@ -18,30 +23,106 @@ var (
 
 type FactUseCase interface {
 	GetFact(ctx context.Context, input GetFactInput) (GetFactOutput, error)
+	GetRelatedFacts(ctx context.Context, input GetRelatedFactsInput) (GetRelatedFactsOutput, error)
+	SearchFacts(ctx context.Context, input SearchFactsInput) (SearchFactsOutput, error)
 }
 
 type FactUseCaseImpl struct {
 	factRepo infrastructure.FactRepository
 
 	recService controller.RecService
+
+	// strategies выбирает между фактом по категории и случайным (config.FeedStrategy*).
+	strategies *weighted.Chooser
+
+	// archive учитывает показы фактов; nil — показы не считаются.
+	archive infrastructure.FactArchive
+
+	// serves пишет журнал показов с вариантами экспериментов; nil — журнал не ведётся.
+	serves infrastructure.ServeLog
+
+	// related — источники похожих фактов; nil — GetRelatedFacts недоступен.
+	related *relatedDeps
+
+	// search — полнотекстовый индекс фактов; nil — SearchFacts недоступен.
+	search infrastructure.FactSearcher
+}
+
+type Option func(*FactUseCaseImpl)
+
+// WithFactArchive включает учёт показов фактов в архиве.
+func WithFactArchive(a infrastructure.FactArchive) Option {
+	return func(uc *FactUseCaseImpl) { uc.archive = a }
 }
 
-func NewFactUseCase(factRepo infrastructure.FactRepository, recService controller.RecService) *FactUseCaseImpl {
-	return &FactUseCaseImpl{
+// WithServeLog включает журнал показов для отчётов по экспериментам.
+func WithServeLog(l infrastructure.ServeLog) Option {
+	return func(uc *FactUseCaseImpl) { uc.serves = l }
+}
+
+func NewFactUseCase(factRepo infrastructure.FactRepository, recService controller.RecService, strategies *weighted.Chooser, opts ...Option) *FactUseCaseImpl {
+	uc := &FactUseCaseImpl{
 		factRepo: factRepo,
 
 		recService: recService,
+
+		strategies: strategies,
+	}
+	for _, o := range opts {
+		o(uc)
 	}
+	return uc
 }
 
 type GetFactInput struct{}
 type GetFactOutput struct {
-	Fact entity.Fact
+	Fact   entity.Fact
+	Reason Reason
+}
+
+// ReasonPath — каким путём выбран факт.
+type ReasonPath string
+
+const (
+	// ReasonPersonal — категория из данных самого пользователя: лайков, сигналов или похожих категорий.
+	ReasonPersonal ReasonPath = "personal"
+	// ReasonTrending — категория, популярная у всех пользователей.
+	ReasonTrending ReasonPath = "trending"
+	// ReasonOnboarding — стартовая категория для пользователя без истории.
+	ReasonOnboarding ReasonPath = "onboarding"
+	// ReasonRandom — выпала стратегия случайного факта.
+	ReasonRandom ReasonPath = "random"
+	// ReasonFallback — по категории фактов не нашлось, рекомендаций нет или фактов нет вовсе.
+	ReasonFallback ReasonPath = "fallback"
+)
+
+// Reason объясняет, почему пользователь видит факт.
+type Reason struct {
+	Path ReasonPath
+	// Category — рекомендованная категория, по которой выбирался факт; при fallback — та,
+	// по которой не нашлось фактов. Пусто для random.
+	Category entity.Category
+	Source   recentity.CategorySource
+	// Via — лайкнутая категория, через которую рекомендована похожая.
+	Via entity.Category
+	// Score — скор категории в её источнике.
+	Score float64
+	// Explored — бандит выбрал не самую сильную рекомендацию, чтобы попробовать другую тему.
+	Explored bool
+}
+
+// sourcePaths сопоставляет источник рекомендации с путём в объяснении.
+var sourcePaths = map[recentity.CategorySource]ReasonPath{
+	recentity.SourceLikes:      ReasonPersonal,
+	recentity.SourceSignals:    ReasonPersonal,
+	recentity.SourceSimilar:    ReasonPersonal,
+	recentity.SourcePopular:    ReasonTrending,
+	recentity.SourceOnboarding: ReasonOnboarding,
 }
 
 func returnDefault() GetFactOutput {
 	return GetFactOutput{
-		entity.Fact{
+		Fact: entity.Fact{
 			ID:        "-1",
 			Title:     "FUN FACT",
 			Summary:   "we currently don't have any facts ready...",
@@ -49,23 +130,39 @@ func returnDefault() GetFactOutput {
 			SourceURL: "",
 			Lang:      "en",
 		},
+		Reason: Reason{Path: ReasonFallback},
 	}
 }
 
 func (uc *FactUseCaseImpl) GetFact(ctx context.Context, input GetFactInput) (GetFactOutput, error) {
 	cats, err := uc.recService.GetUserRec(ctx)
 
-	r := rand.Float64()
-	byCategory := err == nil && len(cats) > 0 && r < 0.6
+	strategies := uc.strategies
+	if override := experiment.StrategyChooser(ctx); override != nil {
+		strategies = override
+	}
+	strategy := strategies.Choose()
+	byCategory := err == nil && len(cats) > 0 && strategy == config.FeedStrategyCategory
 
 	var fact *entity.Fact
 	var category entity.Category
+	var reason Reason
 
 	if byCategory {
-		category = cats[0]
+		chosen := uc.recService.ChooseCategory(ctx, cats)
+		category = chosen.Category
+		reason = Reason{
+			Path:     sourcePaths[chosen.Source],
+			Category: chosen.Category,
+			Source:   chosen.Source,
+			Via:      chosen.Via,
+			Score:    chosen.Score,
+			Explored: chosen.Category != cats[0].Category,
+		}
 		logger.LoggerFromContext(ctx).Info("GetFact: trying by category",
 			zap.String("category", string(category)),
-			zap.Float64("rand", r),
+			zap.String("source", string(chosen.Source)),
+			zap.String("strategy", strategy),
 		)
 
 		facts, err2 := uc.factRepo.GetByCategory(ctx, category, 10)
@@ -73,6 +170,7 @@ func (uc *FactUseCaseImpl) GetFact(ctx context.Context, input GetFactInput) (Get
 			fact = facts[rand.Intn(len(facts))]
 		} else {
 			logger.LoggerFromContext(ctx).Warn("GetFact: empty category, falling back to random", zap.Error(err2), zap.String("category", string(category)))
+			reason.Path = ReasonFallback
 			fact, err = uc.factRepo.PopRandom(ctx)
 
 			zap.L().Warn("fact", zap.Bool("fact is nil", fact == nil))
@@ -83,7 +181,12 @@ func (uc *FactUseCaseImpl) GetFact(ctx context.Context, input GetFactInput) (Get
 
 		}
 	} else {
-		logger.LoggerFromContext(ctx).Info("GetFact: random path", zap.Float64("rand", r))
+		logger.LoggerFromContext(ctx).Info("GetFact: random path", zap.String("strategy", strategy))
+		reason.Path = ReasonRandom
+		if strategy == config.FeedStrategyCategory {
+			// хотели по категории, но рекомендаций нет
+			reason.Path = ReasonFallback
+		}
 		fact, err = uc.factRepo.PopRandom(ctx)
 
 		zap.L().Warn("fact", zap.Bool("fact is nil", fact == nil))
@@ -93,5 +196,35 @@ func (uc *FactUseCaseImpl) GetFact(ctx context.Context, input GetFactInput) (Get
 		}
 	}
 
-	return GetFactOutput{Fact: *fact}, nil
+	if uc.archive != nil {
+		// показ уже состоялся: ошибка учёта не должна ломать выдачу
+		if err := uc.archive.MarkServed(ctx, fact.ID); err != nil {
+			logger.LoggerFromContext(ctx).Warn("GetFact: failed to mark fact served", zap.Error(err))
+		}
+	}
+
+	uc.recordServe(ctx, fact, reason)
+
+	return GetFactOutput{Fact: *fact, Reason: reason}, nil
+}
+
+// recordServe пишет показ в журнал; анонимные показы не записываются — их нельзя отнести к варианту.
+func (uc *FactUseCaseImpl) recordServe(ctx context.Context, fact *entity.Fact, reason Reason) {
+	if uc.serves == nil {
+		return
+	}
+	userID, err := auth.UserIDFromCtx(ctx)
+	if err != nil {
+		return
+	}
+	served := entity.ServedFact{
+		UserID:   string(userID),
+		FactID:   fact.ID,
+		Category: fact.Category,
+		Reason:   string(reason.Path),
+		Variants: experiment.Variants(ctx),
+	}
+	if err := uc.serves.Record(ctx, served); err != nil {
+		logger.LoggerFromContext(ctx).Warn("GetFact: failed to record serve", zap.Error(err))
+	}
 }
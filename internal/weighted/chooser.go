@@ -0,0 +1,153 @@
+package weighted
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+var ErrNoWeights = errors.New("weighted: no positive weights")
+
+// Rand — источник случайности; подменяется в тестах детерминированным генератором.
+type Rand interface {
+	Float64() float64
+}
+
+type globalRand struct{}
+
+func (globalRand) Float64() float64 { return rand.Float64() }
+
+// lockedRand делает *rand.Rand безопасным для конкурентного использования.
+type lockedRand struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+func (l *lockedRand) Float64() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Float64()
+}
+
+// NewSeededRand возвращает потокобезопасный генератор с фиксированным seed.
+func NewSeededRand(seed int64) Rand {
+	return &lockedRand{r: rand.New(rand.NewSource(seed))}
+}
+
+// Chooser выбирает имя с вероятностью, пропорциональной его весу.
+// Веса можно заменить на лету через Set.
+type Chooser struct {
+	mu      sync.RWMutex
+	names   []string
+	weights []float64
+	total   float64
+	rnd     Rand
+}
+
+type Option func(*Chooser)
+
+// WithRand подменяет источник случайности.
+func WithRand(r Rand) Option { return func(c *Chooser) { c.rnd = r } }
+
+func NewChooser(weights map[string]float64, opts ...Option) (*Chooser, error) {
+	c := &Chooser{rnd: globalRand{}}
+	for _, o := range opts {
+		o(c)
+	}
+	if err := c.Set(weights); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Set атомарно заменяет веса. Отрицательные веса и нулевая сумма отклоняются.
+func (c *Chooser) Set(weights map[string]float64) error {
+	names := make([]string, 0, len(weights))
+	var total float64
+	for name, w := range weights {
+		if w < 0 {
+			return fmt.Errorf("weighted: negative weight %v for %q", w, name)
+		}
+		names = append(names, name)
+		total += w
+	}
+	if total <= 0 {
+		return ErrNoWeights
+	}
+	// сортировка делает выбор воспроизводимым при одинаковой последовательности случайных чисел
+	sort.Strings(names)
+
+	ws := make([]float64, len(names))
+	for i, name := range names {
+		ws[i] = weights[name]
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.names = names
+	c.weights = ws
+	c.total = total
+	return nil
+}
+
+// Weights возвращает копию текущих весов.
+func (c *Chooser) Weights() map[string]float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	out := make(map[string]float64, len(c.names))
+	for i, name := range c.names {
+		out[name] = c.weights[i]
+	}
+	return out
+}
+
+// Choose возвращает случайное имя с учётом весов.
+func (c *Chooser) Choose() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.names[c.pick()]
+}
+
+// Order возвращает все имена с положительным весом: первым — случайно выбранное,
+// остальные — по убыванию веса. Удобно для выбора с откатом на следующий вариант.
+func (c *Chooser) Order() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	first := c.pick()
+	rest := make([]int, 0, len(c.names)-1)
+	for i := range c.names {
+		if i != first && c.weights[i] > 0 {
+			rest = append(rest, i)
+		}
+	}
+	sort.SliceStable(rest, func(a, b int) bool { return c.weights[rest[a]] > c.weights[rest[b]] })
+
+	order := make([]string, 0, len(rest)+1)
+	order = append(order, c.names[first])
+	for _, i := range rest {
+		order = append(order, c.names[i])
+	}
+	return order
+}
+
+// pick вызывается под RLock.
+func (c *Chooser) pick() int {
+	x := c.rnd.Float64() * c.total
+	for i, w := range c.weights {
+		if x < w {
+			return i
+		}
+		x -= w
+	}
+	// погрешность округления: берём последний вариант с положительным весом
+	for i := len(c.weights) - 1; i >= 0; i-- {
+		if c.weights[i] > 0 {
+			return i
+		}
+	}
+	return len(c.weights) - 1
+}
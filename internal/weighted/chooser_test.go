@@ -0,0 +1,104 @@
+package weighted
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// fixedRand отдаёт заранее заданную последовательность чисел.
+type fixedRand struct {
+	values []float64
+	i      int
+}
+
+func (f *fixedRand) Float64() float64 {
+	v := f.values[f.i%len(f.values)]
+	f.i++
+	return v
+}
+
+func TestChooseIsDeterministicWithSeed(t *testing.T) {
+	weights := map[string]float64{"a": 1, "b": 2, "c": 3}
+
+	first, err := NewChooser(weights, WithRand(NewSeededRand(42)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := NewChooser(weights, WithRand(NewSeededRand(42)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		if a, b := first.Choose(), second.Choose(); a != b {
+			t.Fatalf("choice %d differs for the same seed: %q vs %q", i, a, b)
+		}
+	}
+}
+
+func TestChooseFollowsWeights(t *testing.T) {
+	c, err := NewChooser(map[string]float64{"rare": 1, "common": 3, "never": 0}, WithRand(NewSeededRand(7)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const n = 20000
+	counts := map[string]int{}
+	for i := 0; i < n; i++ {
+		counts[c.Choose()]++
+	}
+
+	if counts["never"] != 0 {
+		t.Errorf("zero-weight name chosen %d times", counts["never"])
+	}
+	if share := float64(counts["common"]) / n; math.Abs(share-0.75) > 0.02 {
+		t.Errorf("common share = %.3f, want about 0.75", share)
+	}
+}
+
+func TestChooseBoundaries(t *testing.T) {
+	// имена сортируются: a [0, 1), b [1, 3)
+	c, err := NewChooser(map[string]float64{"a": 1, "b": 2}, WithRand(&fixedRand{values: []float64{0, 0.33, 0.34, 0.999999}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []string{"a", "a", "b", "b"} {
+		if got := c.Choose(); got != want {
+			t.Errorf("choice %d = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestOrderPutsChosenFirstThenByWeight(t *testing.T) {
+	c, err := NewChooser(map[string]float64{"a": 1, "b": 5, "c": 3, "z": 0}, WithRand(&fixedRand{values: []float64{0}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := c.Order()
+	want := []string{"a", "b", "c"}
+	if len(got) != len(want) {
+		t.Fatalf("Order() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Order() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestSetRejectsInvalidWeights(t *testing.T) {
+	c, err := NewChooser(map[string]float64{"a": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Set(map[string]float64{"a": 0}); !errors.Is(err, ErrNoWeights) {
+		t.Errorf("Set(zero) error = %v, want ErrNoWeights", err)
+	}
+	if err := c.Set(map[string]float64{"a": -1, "b": 2}); err == nil {
+		t.Error("Set(negative) succeeded")
+	}
+	if got := c.Weights(); got["a"] != 1 || len(got) != 1 {
+		t.Errorf("weights changed after rejected Set: %v", got)
+	}
+}
@@ -0,0 +1,256 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	adminpb "github.com/NordCoder/Story/generated/api/proto/v1"
+	"github.com/NordCoder/Story/internal/entity"
+	"github.com/NordCoder/Story/internal/logger"
+	"github.com/NordCoder/Story/services/admin/usecase"
+	entity2 "github.com/NordCoder/Story/services/authorization/entity"
+	auth "github.com/NordCoder/Story/services/authorization/transport/http"
+	"github.com/NordCoder/Story/services/prefetch"
+	"github.com/NordCoder/Story/services/prefetch/category"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var _ adminpb.AdminServiceServer = (*AdminServiceImpl)(nil)
+
+type AdminServiceImpl struct {
+	usecase usecase.AdminUseCase
+	admins  map[entity2.UserID]struct{}
+}
+
+// NewAdminService создаёт AdminService; доступ получают только пользователи из admins.
+func NewAdminService(usecase usecase.AdminUseCase, admins []string) *AdminServiceImpl {
+	set := make(map[entity2.UserID]struct{}, len(admins))
+	for _, id := range admins {
+		set[entity2.UserID(id)] = struct{}{}
+	}
+	return &AdminServiceImpl{
+		usecase: usecase,
+		admins:  set,
+	}
+}
+
+// authorize проверяет, что запрос пришёл от администратора.
+func (s *AdminServiceImpl) authorize(ctx context.Context) error {
+	id, err := auth.UserIDFromCtx(ctx)
+	if err != nil {
+		logger.LoggerFromContext(ctx).Info("Failed to get id from context", zap.Error(err))
+		return status.Error(codes.Unauthenticated, "user not authenticated")
+	}
+	if _, ok := s.admins[id]; !ok {
+		logger.LoggerFromContext(ctx).Warn("admin access denied", zap.String("user_id", string(id)))
+		return status.Error(codes.PermissionDenied, "admin access required")
+	}
+	return nil
+}
+
+func (s *AdminServiceImpl) ListProviders(ctx context.Context, _ *emptypb.Empty) (*adminpb.ListProvidersResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	providers, err := s.usecase.ListProviders(ctx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	resp := &adminpb.ListProvidersResponse{Providers: make([]*adminpb.ProviderInfo, 0, len(providers))}
+	for _, p := range providers {
+		resp.Providers = append(resp.Providers, &adminpb.ProviderInfo{
+			Name:       p.Name,
+			Weight:     p.Weight,
+			Categories: categoriesToStrings(p.Categories),
+		})
+	}
+	return resp, nil
+}
+
+func (s *AdminServiceImpl) AddProviderCategory(ctx context.Context, req *adminpb.ProviderCategoryRequest) (*emptypb.Empty, error) {
+	if err := req.ValidateAll(); err != nil {
+		logger.LoggerFromContext(ctx).Info("AddProviderCategory validate fail", zap.Error(err))
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	if err := s.usecase.AddProviderCategory(ctx, req.GetProvider(), entity.Category(req.GetCategory())); err != nil {
+		return nil, grpcError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *AdminServiceImpl) RemoveProviderCategory(ctx context.Context, req *adminpb.ProviderCategoryRequest) (*emptypb.Empty, error) {
+	if err := req.ValidateAll(); err != nil {
+		logger.LoggerFromContext(ctx).Info("RemoveProviderCategory validate fail", zap.Error(err))
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	if err := s.usecase.RemoveProviderCategory(ctx, req.GetProvider(), entity.Category(req.GetCategory())); err != nil {
+		return nil, grpcError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *AdminServiceImpl) ListBlockedCategories(ctx context.Context, _ *emptypb.Empty) (*adminpb.ListBlockedCategoriesResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	return &adminpb.ListBlockedCategoriesResponse{
+		Categories: categoriesToStrings(s.usecase.ListBlockedCategories(ctx)),
+	}, nil
+}
+
+func (s *AdminServiceImpl) BlockCategory(ctx context.Context, req *adminpb.AdminCategoryRequest) (*emptypb.Empty, error) {
+	if err := req.ValidateAll(); err != nil {
+		logger.LoggerFromContext(ctx).Info("BlockCategory validate fail", zap.Error(err))
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	if err := s.usecase.BlockCategory(ctx, entity.Category(req.GetCategory())); err != nil {
+		return nil, grpcError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *AdminServiceImpl) UnblockCategory(ctx context.Context, req *adminpb.AdminCategoryRequest) (*emptypb.Empty, error) {
+	if err := req.ValidateAll(); err != nil {
+		logger.LoggerFromContext(ctx).Info("UnblockCategory validate fail", zap.Error(err))
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	if err := s.usecase.UnblockCategory(ctx, entity.Category(req.GetCategory())); err != nil {
+		return nil, grpcError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// TriggerPrefetch возвращает отчёт даже при ошибке Википедии: текст ошибки попадает в поле error.
+func (s *AdminServiceImpl) TriggerPrefetch(ctx context.Context, req *adminpb.AdminCategoryRequest) (*adminpb.PrefetchReport, error) {
+	if err := req.ValidateAll(); err != nil {
+		logger.LoggerFromContext(ctx).Info("TriggerPrefetch validate fail", zap.Error(err))
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	report, err := s.usecase.TriggerPrefetch(ctx, entity.Category(req.GetCategory()))
+	if errors.Is(err, entity.ErrCategoryBlocked) {
+		return nil, grpcError(err)
+	}
+	return reportToProto(report), nil
+}
+
+func (s *AdminServiceImpl) ListPrefetchReports(ctx context.Context, _ *emptypb.Empty) (*adminpb.ListPrefetchReportsResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	reports := s.usecase.ListPrefetchReports(ctx)
+	resp := &adminpb.ListPrefetchReportsResponse{Reports: make([]*adminpb.PrefetchReport, 0, len(reports))}
+	for _, r := range reports {
+		resp.Reports = append(resp.Reports, reportToProto(r))
+	}
+	return resp, nil
+}
+
+// defaultReportPeriod — период отчёта по эксперименту, если since не передан.
+const defaultReportPeriod = 7 * 24 * time.Hour
+
+func (s *AdminServiceImpl) GetExperimentReport(ctx context.Context, req *adminpb.GetExperimentReportRequest) (*adminpb.ExperimentReport, error) {
+	if err := req.ValidateAll(); err != nil {
+		logger.LoggerFromContext(ctx).Info("GetExperimentReport validate fail", zap.Error(err))
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	since := time.Now().Add(-defaultReportPeriod)
+	if req.GetSince() != nil {
+		since = req.GetSince().AsTime()
+	}
+	report, err := s.usecase.ExperimentReport(ctx, req.GetExperiment(), since)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	resp := &adminpb.ExperimentReport{
+		Experiment: report.Experiment,
+		Since:      timestamppb.New(report.Since),
+		Variants:   make([]*adminpb.VariantReport, 0, len(report.Variants)),
+	}
+	for _, v := range report.Variants {
+		resp.Variants = append(resp.Variants, variantToProto(v))
+	}
+	return resp, nil
+}
+
+func variantToProto(v entity.VariantStats) *adminpb.VariantReport {
+	pb := &adminpb.VariantReport{
+		Variant:    v.Variant,
+		Users:      v.Users,
+		Serves:     v.Serves,
+		Likes:      v.Likes,
+		Dislikes:   v.Dislikes,
+		Skips:      v.Skips,
+		Engaged:    v.Engaged,
+		AvgDwellMs: v.AvgDwell.Milliseconds(),
+	}
+	if v.Serves > 0 {
+		pb.LikeRate = float64(v.Likes) / float64(v.Serves)
+		pb.EngagementRate = float64(v.Engaged) / float64(v.Serves)
+	}
+	return pb
+}
+
+func reportToProto(r prefetch.Report) *adminpb.PrefetchReport {
+	pb := &adminpb.PrefetchReport{
+		Category:   string(r.Category),
+		Saved:      int32(r.Saved),
+		Rejected:   int32(r.Rejected),
+		Failed:     int32(r.Failed),
+		Warmed:     int32(r.Warmed),
+		Duplicate:  int32(r.Duplicate),
+		StartedAt:  timestamppb.New(r.StartedAt),
+		DurationMs: r.Duration.Milliseconds(),
+	}
+	if r.Err != nil {
+		pb.Error = r.Err.Error()
+	}
+	return pb
+}
+
+func categoriesToStrings(categories []entity.Category) []string {
+	out := make([]string, len(categories))
+	for i, c := range categories {
+		out[i] = string(c)
+	}
+	return out
+}
+
+// grpcError маппит ошибки админки на gRPC-статусы.
+func grpcError(err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrProviderNotFound), errors.Is(err, usecase.ErrExperimentNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, entity.ErrCategoryBlocked), errors.Is(err, category.ErrReadOnlyProvider):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, usecase.ErrExperimentsOff):
+		return status.Error(codes.Unavailable, err.Error())
+	default:
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/NordCoder/Story/internal/entity"
+	"github.com/NordCoder/Story/internal/experiment"
+	"github.com/NordCoder/Story/internal/infrastructure"
+	"github.com/NordCoder/Story/internal/logger"
+	"github.com/NordCoder/Story/services/prefetch"
+	"github.com/NordCoder/Story/services/prefetch/category"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrProviderNotFound   = errors.New("category provider not found")
+	ErrExperimentNotFound = errors.New("experiment not found")
+	ErrExperimentsOff     = errors.New("experiment reports are disabled")
+)
+
+// ProviderCategories — категории и вес одного провайдера.
+type ProviderCategories struct {
+	Name       string
+	Weight     float64
+	Categories []entity.Category
+}
+
+// ExperimentReport — вовлечённость по вариантам эксперимента за период с Since.
+type ExperimentReport struct {
+	Experiment string
+	Since      time.Time
+	Variants   []entity.VariantStats
+}
+
+type AdminUseCase interface {
+	ListProviders(ctx context.Context) ([]ProviderCategories, error)
+	AddProviderCategory(ctx context.Context, provider string, category entity.Category) error
+	RemoveProviderCategory(ctx context.Context, provider string, category entity.Category) error
+	ListBlockedCategories(ctx context.Context) []entity.Category
+	BlockCategory(ctx context.Context, category entity.Category) error
+	UnblockCategory(ctx context.Context, category entity.Category) error
+	TriggerPrefetch(ctx context.Context, category entity.Category) (prefetch.Report, error)
+	ListPrefetchReports(ctx context.Context) []prefetch.Report
+	ExperimentReport(ctx context.Context, experiment string, since time.Time) (ExperimentReport, error)
+}
+
+type AdminUseCaseImpl struct {
+	providers  *category.Registry
+	blocklist  *category.Blocklist
+	prefetcher prefetch.Prefetcher
+
+	// experiments и serves нужны для отчётов по экспериментам; nil — отчёты недоступны.
+	experiments *experiment.Assigner
+	serves      infrastructure.ServeLog
+}
+
+type Option func(*AdminUseCaseImpl)
+
+// WithExperiments включает отчёты по экспериментам из журнала показов.
+func WithExperiments(assigner *experiment.Assigner, serves infrastructure.ServeLog) Option {
+	return func(a *AdminUseCaseImpl) {
+		a.experiments = assigner
+		a.serves = serves
+	}
+}
+
+func NewAdminUseCase(
+	providers *category.Registry,
+	blocklist *category.Blocklist,
+	prefetcher prefetch.Prefetcher,
+	opts ...Option,
+) AdminUseCase {
+	a := &AdminUseCaseImpl{
+		providers:  providers,
+		blocklist:  blocklist,
+		prefetcher: prefetcher,
+	}
+	for _, o := range opts {
+		o(a)
+	}
+	return a
+}
+
+func (a *AdminUseCaseImpl) ListProviders(ctx context.Context) ([]ProviderCategories, error) {
+	names := a.providers.Names()
+	weights := a.providers.Weights()
+
+	result := make([]ProviderCategories, 0, len(names))
+	for _, name := range names {
+		p, _ := a.providers.Get(name)
+		categories, err := p.GetCategories(ctx)
+		if err != nil {
+			logger.LoggerFromContext(ctx).Error("failed to list provider categories", zap.String("provider", name), zap.Error(err))
+			return nil, err
+		}
+		result = append(result, ProviderCategories{Name: name, Weight: weights[name], Categories: categories})
+	}
+	return result, nil
+}
+
+func (a *AdminUseCaseImpl) AddProviderCategory(ctx context.Context, provider string, category entity.Category) error {
+	p, ok := a.providers.Get(provider)
+	if !ok {
+		return ErrProviderNotFound
+	}
+	if a.blocklist.IsBlocked(category) {
+		return entity.ErrCategoryBlocked
+	}
+	logger.LoggerFromContext(ctx).Info("admin: adding category", zap.String("provider", provider), zap.String("category", string(category)))
+	return p.AddCategory(ctx, category)
+}
+
+func (a *AdminUseCaseImpl) RemoveProviderCategory(ctx context.Context, provider string, category entity.Category) error {
+	p, ok := a.providers.Get(provider)
+	if !ok {
+		return ErrProviderNotFound
+	}
+	logger.LoggerFromContext(ctx).Info("admin: removing category", zap.String("provider", provider), zap.String("category", string(category)))
+	return p.RemoveCategory(ctx, category)
+}
+
+func (a *AdminUseCaseImpl) ListBlockedCategories(_ context.Context) []entity.Category {
+	return a.blocklist.List()
+}
+
+// BlockCategory блокирует категорию и убирает её из всех провайдеров, которые это поддерживают.
+func (a *AdminUseCaseImpl) BlockCategory(ctx context.Context, category entity.Category) error {
+	logger.LoggerFromContext(ctx).Info("admin: blocking category", zap.String("category", string(category)))
+	if err := a.blocklist.Block(ctx, category); err != nil {
+		logger.LoggerFromContext(ctx).Error("failed to block category", zap.String("category", string(category)), zap.Error(err))
+		return err
+	}
+
+	for _, name := range a.providers.Names() {
+		p, _ := a.providers.Get(name)
+		if err := p.RemoveCategory(ctx, category); err != nil {
+			logger.LoggerFromContext(ctx).Warn("failed to remove blocked category from provider", zap.String("provider", name), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+func (a *AdminUseCaseImpl) UnblockCategory(ctx context.Context, category entity.Category) error {
+	logger.LoggerFromContext(ctx).Info("admin: unblocking category", zap.String("category", string(category)))
+	if err := a.blocklist.Unblock(ctx, category); err != nil {
+		logger.LoggerFromContext(ctx).Error("failed to unblock category", zap.String("category", string(category)), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (a *AdminUseCaseImpl) TriggerPrefetch(ctx context.Context, category entity.Category) (prefetch.Report, error) {
+	logger.LoggerFromContext(ctx).Info("admin: triggering prefetch", zap.String("category", string(category)))
+	return a.prefetcher.PrefetchCategory(ctx, category)
+}
+
+func (a *AdminUseCaseImpl) ListPrefetchReports(_ context.Context) []prefetch.Report {
+	return a.prefetcher.Reports()
+}
+
+// ExperimentReport сравнивает варианты эксперимента. Выключенный эксперимент тоже можно
+// посмотреть: его показы остаются в журнале.
+func (a *AdminUseCaseImpl) ExperimentReport(ctx context.Context, name string, since time.Time) (ExperimentReport, error) {
+	if a.experiments == nil || a.serves == nil {
+		return ExperimentReport{}, ErrExperimentsOff
+	}
+	if !a.experiments.Known(name) {
+		return ExperimentReport{}, ErrExperimentNotFound
+	}
+	variants, err := a.serves.Report(ctx, name, since)
+	if err != nil {
+		logger.LoggerFromContext(ctx).Error("failed to build experiment report", zap.String("experiment", name), zap.Error(err))
+		return ExperimentReport{}, err
+	}
+	return ExperimentReport{Experiment: name, Since: since, Variants: variants}, nil
+}
@@ -17,6 +17,9 @@ type AuthConfig struct {
 	AccessTokenTTL  time.Duration `mapstructure:"access_token_ttl"`
 	RefreshTokenTTL time.Duration `mapstructure:"refresh_token_ttl"`
 
+	// Admins — ID пользователей, которым доступен AdminService.
+	Admins []string `mapstructure:"admins"`
+
 	// Database
 	DB struct {
 		URL             string
@@ -14,15 +14,17 @@ import (
 var embedMigrations embed.FS
 
 func SetupPostgres(pool *pgxpool.Pool, logger *zap.Logger) {
-	goose.SetBaseFS(embedMigrations)
-	if err := goose.SetDialect("postgres"); err != nil {
-		logger.Error("can not set dialect in goose", zap.Error(err))
+	if err := Migrate(pool); err != nil {
+		logger.Error("can not setup migrations", zap.Error(err))
 		os.Exit(1)
 	}
+}
 
-	db := stdlib.OpenDBFromPool(pool)
-	if err := goose.Up(db, "migrations"); err != nil {
-		logger.Error("can not setup migrations", zap.Error(err))
-		os.Exit(1)
+// Migrate применяет все миграции, которых ещё нет в базе.
+func Migrate(pool *pgxpool.Pool) error {
+	goose.SetBaseFS(embedMigrations)
+	if err := goose.SetDialect("postgres"); err != nil {
+		return err
 	}
+	return goose.Up(stdlib.OpenDBFromPool(pool), "migrations")
 }
@@ -0,0 +1,22 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS facts_archive (
+    id              UUID        PRIMARY KEY,
+    category        TEXT        NOT NULL,
+    title           TEXT        NOT NULL,
+    summary         TEXT        NOT NULL,
+    image_url       TEXT        NOT NULL DEFAULT '',
+    source_url      TEXT        NOT NULL,
+    lang            TEXT        NOT NULL DEFAULT '',
+    fetched_at      TIMESTAMPTZ NOT NULL,
+    archived_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    serve_count     BIGINT      NOT NULL DEFAULT 0,
+    last_served_at  TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_facts_archive_category ON facts_archive (category, serve_count);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_facts_archive_category;
+DROP TABLE IF EXISTS facts_archive;
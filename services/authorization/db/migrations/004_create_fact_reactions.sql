@@ -0,0 +1,23 @@
+-- +goose Up
+
+-- сырые реакции пользователей на факты; хранятся целиком для последующего обучения моделей
+CREATE TABLE IF NOT EXISTS fact_reactions (
+    id          BIGSERIAL   PRIMARY KEY,
+    user_id     UUID        NOT NULL,
+    fact_id     UUID        NOT NULL,
+    category    TEXT        NOT NULL,
+    reaction    TEXT        NOT NULL,
+    dwell_ms    BIGINT      NOT NULL DEFAULT 0,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_fact_reactions_user
+        FOREIGN KEY(user_id)
+            REFERENCES users(id)
+            ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_fact_reactions_user ON fact_reactions (user_id, created_at);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_fact_reactions_user;
+DROP TABLE IF EXISTS fact_reactions;
@@ -0,0 +1,26 @@
+-- +goose Up
+
+-- неявные сигналы от клиентов (RecordEvents); event_id генерирует клиент,
+-- уникальность делает повторную доставку идемпотентной
+CREATE TABLE IF NOT EXISTS user_events (
+    id           BIGSERIAL   PRIMARY KEY,
+    event_id     UUID        NOT NULL UNIQUE,
+    user_id      UUID        NOT NULL,
+    fact_id      UUID        NOT NULL,
+    category     TEXT        NOT NULL DEFAULT '',
+    event_type   TEXT        NOT NULL,
+    duration_ms  BIGINT      NOT NULL DEFAULT 0,
+    occurred_at  TIMESTAMPTZ NOT NULL,
+    received_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_user_events_user
+        FOREIGN KEY(user_id)
+            REFERENCES users(id)
+            ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_events_user ON user_events (user_id, occurred_at);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_user_events_user;
+DROP TABLE IF EXISTS user_events;
@@ -0,0 +1,11 @@
+-- +goose Up
+
+-- распространение лайка на подкатегории добавляет дробные веса (decay^depth),
+-- поэтому целочисленный счётчик заменяется вещественным скором
+ALTER TABLE user_category_likes RENAME COLUMN cnt TO score;
+ALTER TABLE user_category_likes ALTER COLUMN score TYPE DOUBLE PRECISION;
+
+-- +goose Down
+
+ALTER TABLE user_category_likes ALTER COLUMN score TYPE BIGINT USING round(score)::BIGINT;
+ALTER TABLE user_category_likes RENAME COLUMN score TO cnt;
@@ -0,0 +1,12 @@
+-- +goose Up
+
+-- агрегатор трендов выбирает сигналы всех пользователей за окно по времени
+CREATE INDEX IF NOT EXISTS idx_fact_reactions_created_at ON fact_reactions (created_at);
+CREATE INDEX IF NOT EXISTS idx_user_category_likes_updated_at ON user_category_likes (updated_at);
+CREATE INDEX IF NOT EXISTS idx_facts_archive_last_served_at ON facts_archive (last_served_at);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_facts_archive_last_served_at;
+DROP INDEX IF EXISTS idx_user_category_likes_updated_at;
+DROP INDEX IF EXISTS idx_fact_reactions_created_at;
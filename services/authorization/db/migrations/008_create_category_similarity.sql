@@ -0,0 +1,21 @@
+-- +goose Up
+
+-- явный дизлайк категории (UnlikeCategory); такие категории не рекомендуются,
+-- пока пользователь снова её не лайкнет
+ALTER TABLE user_category_likes ADD COLUMN IF NOT EXISTS unliked BOOLEAN NOT NULL DEFAULT false;
+
+-- item-item модель: для категории — похожие по совместным лайкам пользователей (косинусная мера).
+-- Таблицу целиком пересобирает фоновая задача
+CREATE TABLE IF NOT EXISTS category_similarity (
+    category    TEXT             NOT NULL,
+    similar     TEXT             NOT NULL,
+    score       DOUBLE PRECISION NOT NULL,
+    co_likes    BIGINT           NOT NULL,
+    built_at    TIMESTAMPTZ      NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (category, similar)
+);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS category_similarity;
+ALTER TABLE user_category_likes DROP COLUMN IF EXISTS unliked;
@@ -0,0 +1,20 @@
+-- +goose Up
+
+-- явное состояние лайка вместо слепого счётчика: повторный лайк или дизлайк ничего не меняет
+ALTER TABLE user_category_likes ADD COLUMN IF NOT EXISTS state TEXT NOT NULL DEFAULT 'none'
+    CHECK (state IN ('none', 'liked', 'unliked'));
+UPDATE user_category_likes SET state = 'unliked' WHERE unliked;
+ALTER TABLE user_category_likes DROP COLUMN IF EXISTS unliked;
+
+-- скор больше не уходит ниже нуля
+UPDATE user_category_likes SET score = 0 WHERE score < 0;
+
+-- ListMyCategories листает категории пользователя
+CREATE INDEX IF NOT EXISTS idx_user_category_likes_user ON user_category_likes (user_id, score DESC);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_user_category_likes_user;
+ALTER TABLE user_category_likes ADD COLUMN IF NOT EXISTS unliked BOOLEAN NOT NULL DEFAULT false;
+UPDATE user_category_likes SET unliked = true WHERE state = 'unliked';
+ALTER TABLE user_category_likes DROP COLUMN IF EXISTS state;
@@ -0,0 +1,27 @@
+-- +goose Up
+
+-- журнал показов фактов с вариантами экспериментов, в которых состоял пользователь
+CREATE TABLE IF NOT EXISTS fact_serves (
+    id          BIGSERIAL   PRIMARY KEY,
+    user_id     UUID        NOT NULL,
+    fact_id     UUID        NOT NULL,
+    category    TEXT        NOT NULL DEFAULT '',
+    reason      TEXT        NOT NULL DEFAULT '',
+    variants    JSONB       NOT NULL DEFAULT '{}'::jsonb,
+    served_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_fact_serves_user
+        FOREIGN KEY(user_id)
+            REFERENCES users(id)
+            ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_fact_serves_served_at ON fact_serves (served_at);
+CREATE INDEX IF NOT EXISTS idx_fact_reactions_user_fact ON fact_reactions (user_id, fact_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_user_events_user_fact ON user_events (user_id, fact_id, occurred_at);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_user_events_user_fact;
+DROP INDEX IF EXISTS idx_fact_reactions_user_fact;
+DROP INDEX IF EXISTS idx_fact_serves_served_at;
+DROP TABLE IF EXISTS fact_serves;
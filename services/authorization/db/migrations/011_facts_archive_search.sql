@@ -0,0 +1,24 @@
+-- +goose Up
+
+-- раньше язык факта не заполнялся: восстанавливаем его по адресу статьи
+UPDATE facts_archive
+SET lang = substring(source_url FROM '^https?://([a-z-]+)\.wikipedia\.org')
+WHERE lang = '' AND source_url ~ '^https?://[a-z-]+\.wikipedia\.org';
+
+-- полнотекстовый индекс по заголовку (вес A) и тексту (вес B). Колонка вычисляемая, поэтому
+-- остаётся в синхроне с каждым фактом, который префетчер сохраняет в архив.
+-- Конфигурация russian стеммит и латиницу английским стеммером, поэтому подходит для всего,
+-- кроме явно английских статей.
+ALTER TABLE facts_archive ADD COLUMN IF NOT EXISTS search tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector(CASE WHEN lang = 'en' THEN 'english'::regconfig ELSE 'russian'::regconfig END, title), 'A') ||
+    setweight(to_tsvector(CASE WHEN lang = 'en' THEN 'english'::regconfig ELSE 'russian'::regconfig END, summary), 'B')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_facts_archive_search ON facts_archive USING GIN (search);
+CREATE INDEX IF NOT EXISTS idx_fact_serves_user_fact ON fact_serves (user_id, fact_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_fact_serves_user_fact;
+DROP INDEX IF EXISTS idx_facts_archive_search;
+ALTER TABLE facts_archive DROP COLUMN IF EXISTS search;
@@ -0,0 +1,36 @@
+-- +goose Up
+
+-- префетчер сохранял одну и ту же статью под новыми ID при каждой загрузке.
+-- Оставляем по одной записи на source_url: самую раннюю. Показы дублей суммируются в неё,
+-- а ссылки на дубли в журналах переводятся на оставшийся ID.
+CREATE TEMP TABLE facts_archive_dups ON COMMIT DROP AS
+SELECT id, keep_id
+FROM (
+    SELECT id,
+           first_value(id) OVER (PARTITION BY source_url ORDER BY archived_at, id) AS keep_id
+    FROM facts_archive
+) ranked
+WHERE id <> keep_id;
+
+UPDATE facts_archive a
+SET serve_count    = a.serve_count + d.serve_count,
+    last_served_at = GREATEST(a.last_served_at, d.last_served_at)
+FROM (
+    SELECT dups.keep_id, SUM(f.serve_count) AS serve_count, MAX(f.last_served_at) AS last_served_at
+    FROM facts_archive_dups dups
+    JOIN facts_archive f ON f.id = dups.id
+    GROUP BY dups.keep_id
+) d
+WHERE a.id = d.keep_id;
+
+UPDATE fact_serves t SET fact_id = d.keep_id FROM facts_archive_dups d WHERE t.fact_id = d.id;
+UPDATE fact_reactions t SET fact_id = d.keep_id FROM facts_archive_dups d WHERE t.fact_id = d.id;
+UPDATE user_events t SET fact_id = d.keep_id FROM facts_archive_dups d WHERE t.fact_id = d.id;
+
+DELETE FROM facts_archive a USING facts_archive_dups d WHERE a.id = d.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_facts_archive_source_url ON facts_archive (source_url);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_facts_archive_source_url;
@@ -0,0 +1,30 @@
+-- +goose Up
+
+-- конфигурация разбора хранится у каждой статьи: en → english, ru → russian, прочие языки
+-- и пустой → simple. Раньше всё, кроме en, шло через russian, и русский стеммер
+-- искажал статьи на других языках.
+ALTER TABLE facts_archive DROP COLUMN IF EXISTS search;
+
+ALTER TABLE facts_archive ADD COLUMN search_config regconfig GENERATED ALWAYS AS (
+    CASE lang WHEN 'en' THEN 'english'::regconfig WHEN 'ru' THEN 'russian'::regconfig ELSE 'simple'::regconfig END
+) STORED;
+
+-- вычисляемая колонка не может ссылаться на другую вычисляемую, поэтому выбор конфигурации повторён
+ALTER TABLE facts_archive ADD COLUMN search tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector(CASE lang WHEN 'en' THEN 'english'::regconfig WHEN 'ru' THEN 'russian'::regconfig ELSE 'simple'::regconfig END, title), 'A') ||
+    setweight(to_tsvector(CASE lang WHEN 'en' THEN 'english'::regconfig WHEN 'ru' THEN 'russian'::regconfig ELSE 'simple'::regconfig END, summary), 'B')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_facts_archive_search ON facts_archive USING GIN (search);
+
+-- +goose Down
+
+ALTER TABLE facts_archive DROP COLUMN IF EXISTS search;
+ALTER TABLE facts_archive DROP COLUMN IF EXISTS search_config;
+
+ALTER TABLE facts_archive ADD COLUMN search tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector(CASE WHEN lang = 'en' THEN 'english'::regconfig ELSE 'russian'::regconfig END, title), 'A') ||
+    setweight(to_tsvector(CASE WHEN lang = 'en' THEN 'english'::regconfig ELSE 'russian'::regconfig END, summary), 'B')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_facts_archive_search ON facts_archive USING GIN (search);
@@ -29,6 +29,7 @@ type SessionRepository interface {
 	// Returns ErrRefreshNotFound if the token is missing or expired.
 	GetUserIDByRefreshToken(ctx context.Context, token string) (entity.UserID, error)
 
-	// RotateRefreshToken atomically replaces an old refresh token with a new one for a user.
+	// RotateRefreshToken replaces an old refresh token with a new one for a user.
+	// Returns ErrRefreshNotFound if the old token is already gone, e.g. rotated concurrently.
 	RotateRefreshToken(ctx context.Context, oldToken, newToken string, userID entity.UserID, ttl time.Duration) error
 }
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/NordCoder/Story/services/authorization/entity"
+)
+
+var _ SessionRepository = (*MemorySessionRepository)(nil)
+
+// MemorySessionRepository keeps refresh tokens in process memory, for running without Redis.
+// Sessions do not survive a restart and are not shared between replicas; expired tokens
+// are removed on lookup.
+type MemorySessionRepository struct {
+	mu     sync.Mutex
+	now    func() time.Time
+	tokens map[string]memorySession
+}
+
+type memorySession struct {
+	userID    entity.UserID
+	expiresAt time.Time // zero value means no expiration
+}
+
+// NewMemorySessionRepository creates an empty in-memory session store.
+func NewMemorySessionRepository() *MemorySessionRepository {
+	return &MemorySessionRepository{now: time.Now, tokens: make(map[string]memorySession)}
+}
+
+// SaveRefreshToken stores a refresh token; ttl <= 0 keeps it until deleted, like SET without EX.
+func (r *MemorySessionRepository) SaveRefreshToken(_ context.Context, token string, userID entity.UserID, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.save(token, userID, ttl)
+	return nil
+}
+
+// GetUserIDByRefreshToken returns ErrRefreshNotFound for missing or expired tokens.
+func (r *MemorySessionRepository) GetUserIDByRefreshToken(_ context.Context, token string) (entity.UserID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.tokens[token]
+	if !ok {
+		return "", ErrRefreshNotFound
+	}
+	if !s.expiresAt.IsZero() && !r.now().Before(s.expiresAt) {
+		delete(r.tokens, token)
+		return "", ErrRefreshNotFound
+	}
+	return s.userID, nil
+}
+
+// DeleteRefreshToken removes a refresh token.
+func (r *MemorySessionRepository) DeleteRefreshToken(_ context.Context, token string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.tokens, token)
+	return nil
+}
+
+// RotateRefreshToken replaces oldToken with newToken under one lock.
+func (r *MemorySessionRepository) RotateRefreshToken(_ context.Context, oldToken, newToken string, userID entity.UserID, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.tokens[oldToken]
+	delete(r.tokens, oldToken)
+	if !ok || !s.expiresAt.IsZero() && !r.now().Before(s.expiresAt) {
+		return ErrRefreshNotFound
+	}
+	r.save(newToken, userID, ttl)
+	return nil
+}
+
+// save must be called with mu held.
+func (r *MemorySessionRepository) save(token string, userID entity.UserID, ttl time.Duration) {
+	s := memorySession{userID: userID}
+	if ttl > 0 {
+		s.expiresAt = r.now().Add(ttl)
+	}
+	r.tokens[token] = s
+}
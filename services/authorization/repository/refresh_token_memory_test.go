@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemorySessionRepositoryRotate(t *testing.T) {
+	ctx := context.Background()
+	r := NewMemorySessionRepository()
+
+	if err := r.SaveRefreshToken(ctx, "old", "user", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.RotateRefreshToken(ctx, "old", "new", "user", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.GetUserIDByRefreshToken(ctx, "old"); !errors.Is(err, ErrRefreshNotFound) {
+		t.Errorf("old token error = %v, want ErrRefreshNotFound", err)
+	}
+	if id, err := r.GetUserIDByRefreshToken(ctx, "new"); err != nil || id != "user" {
+		t.Errorf("new token = %q, %v; want user", id, err)
+	}
+	if err := r.RotateRefreshToken(ctx, "old", "again", "user", time.Hour); !errors.Is(err, ErrRefreshNotFound) {
+		t.Errorf("second rotation error = %v, want ErrRefreshNotFound", err)
+	}
+	if _, err := r.GetUserIDByRefreshToken(ctx, "again"); !errors.Is(err, ErrRefreshNotFound) {
+		t.Errorf("token from failed rotation error = %v, want ErrRefreshNotFound", err)
+	}
+}
+
+func TestMemorySessionRepositoryExpiry(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := NewMemorySessionRepository()
+	r.now = func() time.Time { return now }
+
+	if err := r.SaveRefreshToken(ctx, "token", "user", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(2 * time.Minute)
+	if _, err := r.GetUserIDByRefreshToken(ctx, "token"); !errors.Is(err, ErrRefreshNotFound) {
+		t.Errorf("expired token error = %v, want ErrRefreshNotFound", err)
+	}
+}
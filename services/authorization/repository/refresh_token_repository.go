@@ -11,9 +11,10 @@ import (
 )
 
 // RefreshTokenRepository manages storage of refresh tokens in Redis.
-// Keys are formatted with a prefix and the token string.
+// Keys are formatted with a prefix and the token string. Every command touches a
+// single key, so tokens spread over the whole cluster and need no hash tag.
 type RefreshTokenRepository struct {
-	client    *redis.Client
+	client    redis.UniversalClient
 	ttl       time.Duration
 	keyPrefix string
 }
@@ -23,20 +24,10 @@ func (r *RefreshTokenRepository) key(token string) string {
 	return fmt.Sprintf(r.keyPrefix, token)
 }
 
-// save writes the token->userID mapping with TTL into Redis.
-func (r *RefreshTokenRepository) save(ctx context.Context, cli redis.Cmdable, token string, userID entity.UserID, ttl time.Duration) {
-	cli.Set(ctx, r.key(token), string(userID), ttl)
-}
-
-// del removes the token key in Redis.
-func (r *RefreshTokenRepository) del(ctx context.Context, cli redis.Cmdable, token string) {
-	cli.Del(ctx, r.key(token))
-}
-
 // NewRefreshTokenRepository creates a new RefreshTokenRepository.
 // ttl is the default expiration for refresh tokens.
 // By default, keyPrefix="auth:refresh:%s" but can be overridden with WithKeyPrefix.
-func NewRefreshTokenRepository(client *redis.Client, ttl time.Duration, opts ...Option) *RefreshTokenRepository {
+func NewRefreshTokenRepository(client redis.UniversalClient, ttl time.Duration, opts ...Option) *RefreshTokenRepository {
 	repo := &RefreshTokenRepository{
 		client:    client,
 		ttl:       ttl,
@@ -65,8 +56,7 @@ func (r *RefreshTokenRepository) SaveRefreshToken(ctx context.Context, token str
 // GetUserIDByRefreshToken retrieves the user ID for a given refresh token.
 // Returns ErrRefreshNotFound if the token does not exist or is expired.
 func (r *RefreshTokenRepository) GetUserIDByRefreshToken(ctx context.Context, token string) (entity.UserID, error) {
-	key := fmt.Sprintf(r.keyPrefix, token)
-	val, err := r.client.Get(ctx, key).Result()
+	val, err := r.client.Get(ctx, r.key(token)).Result()
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
 			return "", ErrRefreshNotFound
@@ -82,13 +72,19 @@ func (r *RefreshTokenRepository) DeleteRefreshToken(ctx context.Context, token s
 	return res.Err()
 }
 
-// RotateRefreshToken atomically replaces an old refresh token with a new one for a user.
+// RotateRefreshToken replaces an old refresh token with a new one for a user.
+// The old and new keys live in different cluster slots, so instead of MULTI the
+// old token is claimed with DEL first: of concurrent rotations of the same token
+// only one saves its new token, the others get ErrRefreshNotFound.
 func (r *RefreshTokenRepository) RotateRefreshToken(ctx context.Context, oldToken, newToken string, userID entity.UserID, ttl time.Duration) error {
-	pipe := r.client.TxPipeline()
-	r.del(ctx, pipe, oldToken)
-	r.save(ctx, pipe, newToken, userID, ttl)
-	_, err := pipe.Exec(ctx)
-	return err
+	deleted, err := r.client.Del(ctx, r.key(oldToken)).Result()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrRefreshNotFound
+	}
+	return r.client.Set(ctx, r.key(newToken), string(userID), ttl).Err()
 }
 
 // ErrRefreshNotFound is returned when a refresh token is not found in storage.
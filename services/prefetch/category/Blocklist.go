@@ -0,0 +1,132 @@
+package category
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/NordCoder/Story/internal/entity"
+	"go.uber.org/zap"
+)
+
+// BlocklistStore хранит блокировки вне процесса, чтобы они переживали рестарт
+// и были общими для всех реплик.
+type BlocklistStore interface {
+	Load(ctx context.Context) ([]entity.Category, error)
+	Add(ctx context.Context, category entity.Category) error
+	Remove(ctx context.Context, category entity.Category) error
+}
+
+// Blocklist хранит категории, которые запрещено загружать, независимо от провайдера.
+// Без store блокировки живут только в памяти процесса и теряются при рестарте.
+// Со store изменения сначала пишутся в него, а IsBlocked читает копию в памяти,
+// которую Run периодически сверяет со store: блокировка, сделанная на другой реплике,
+// вступает в силу здесь не позже чем через интервал синхронизации.
+type Blocklist struct {
+	mu         sync.RWMutex
+	categories map[entity.Category]struct{}
+	store      BlocklistStore
+}
+
+func NewBlocklist(categories ...entity.Category) *Blocklist {
+	b := &Blocklist{categories: make(map[entity.Category]struct{}, len(categories))}
+	for _, c := range categories {
+		b.categories[c] = struct{}{}
+	}
+	return b
+}
+
+// NewPersistentBlocklist создаёт Blocklist поверх store и загружает из него текущие блокировки.
+func NewPersistentBlocklist(ctx context.Context, store BlocklistStore) (*Blocklist, error) {
+	b := &Blocklist{categories: make(map[entity.Category]struct{}), store: store}
+	if err := b.Sync(ctx); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (b *Blocklist) Block(ctx context.Context, category entity.Category) error {
+	if b.store != nil {
+		if err := b.store.Add(ctx, category); err != nil {
+			return fmt.Errorf("persist blocked category: %w", err)
+		}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.categories[category] = struct{}{}
+	return nil
+}
+
+func (b *Blocklist) Unblock(ctx context.Context, category entity.Category) error {
+	if b.store != nil {
+		if err := b.store.Remove(ctx, category); err != nil {
+			return fmt.Errorf("persist unblocked category: %w", err)
+		}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.categories, category)
+	return nil
+}
+
+func (b *Blocklist) IsBlocked(category entity.Category) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	_, ok := b.categories[category]
+	return ok
+}
+
+// List возвращает заблокированные категории в алфавитном порядке.
+func (b *Blocklist) List() []entity.Category {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	categories := make([]entity.Category, 0, len(b.categories))
+	for c := range b.categories {
+		categories = append(categories, c)
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i] < categories[j] })
+	return categories
+}
+
+// Sync заменяет копию в памяти содержимым store; без store ничего не делает.
+func (b *Blocklist) Sync(ctx context.Context) error {
+	if b.store == nil {
+		return nil
+	}
+	categories, err := b.store.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("load blocklist: %w", err)
+	}
+
+	set := make(map[entity.Category]struct{}, len(categories))
+	for _, c := range categories {
+		set[c] = struct{}{}
+	}
+	b.mu.Lock()
+	b.categories = set
+	b.mu.Unlock()
+	return nil
+}
+
+// Run синхронизирует блокировки со store каждые interval, пока ctx не отменён.
+// При ошибке остаётся прежняя копия: лучше старый список, чем пустой.
+func (b *Blocklist) Run(ctx context.Context, interval time.Duration, logger *zap.Logger) {
+	if b.store == nil {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := b.Sync(ctx); err != nil {
+				logger.Warn("blocklist sync failed", zap.Error(err))
+			}
+		}
+	}
+}
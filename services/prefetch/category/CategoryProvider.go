@@ -12,4 +12,16 @@ type Provider interface {
 	GetCategories(ctx context.Context) ([]entity.Category, error)
 	SetCategories(ctx context.Context, category []entity.Category) error
 	AddCategory(ctx context.Context, category entity.Category) error
+	RemoveCategory(ctx context.Context, category entity.Category) error
+}
+
+// removeCategory возвращает срез без всех вхождений category.
+func removeCategory(categories []entity.Category, category entity.Category) []entity.Category {
+	filtered := categories[:0:0]
+	for _, c := range categories {
+		if c != category {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
 }
@@ -0,0 +1,249 @@
+package category
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/NordCoder/Story/internal/entity"
+	"github.com/NordCoder/Story/internal/infrastructure/wikipedia"
+	"github.com/NordCoder/Story/services/prefetch/config"
+	"go.uber.org/zap"
+)
+
+// crawledCategory — узел дерева подкатегорий с глубиной и весом выбора.
+type crawledCategory struct {
+	category entity.Category
+	depth    int
+	weight   float64
+}
+
+// CrawlerProvider обходит подкатегории заданных корней в ширину,
+// кэширует полученное дерево и отдаёт взвешенно-случайные категории:
+// чем глубже категория, тем меньше её вес (depth_decay^depth).
+type CrawlerProvider struct {
+	cfg        *config.CrawlerConfig
+	wikiClient wikipedia.WikiClient
+	logger     *zap.Logger
+
+	mu         sync.RWMutex
+	roots      []entity.Category
+	nodes      []crawledCategory
+	cumWeights []float64
+	crawledAt  time.Time
+}
+
+// NewCrawlerProvider создаёт провайдер; до первого обхода он отдаёт корневые категории.
+func NewCrawlerProvider(cfg *config.CrawlerConfig, wikiClient wikipedia.WikiClient, logger *zap.Logger) *CrawlerProvider {
+	p := &CrawlerProvider{
+		cfg:        cfg,
+		wikiClient: wikiClient,
+		logger:     logger,
+		roots:      append([]entity.Category(nil), cfg.Roots...),
+	}
+	p.setNodes(p.rootNodes())
+	return p
+}
+
+// Run обходит дерево сразу и затем раз в refresh_interval, пока жив ctx.
+func (p *CrawlerProvider) Run(ctx context.Context) {
+	if err := p.Refresh(ctx); err != nil {
+		p.logger.Error("Initial category crawl failed", zap.Error(err))
+	}
+
+	ticker := time.NewTicker(p.cfg.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.logger.Info("Category crawler stopping gracefully")
+			return
+		case <-ticker.C:
+			if err := p.Refresh(ctx); err != nil {
+				p.logger.Error("Category crawl failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Refresh заново обходит подкатегории корней и подменяет закэшированное дерево.
+// Ошибки на отдельных узлах пропускаются, обход продолжается с остальными.
+func (p *CrawlerProvider) Refresh(ctx context.Context) error {
+	p.mu.RLock()
+	roots := append([]entity.Category(nil), p.roots...)
+	p.mu.RUnlock()
+
+	visited := make(map[entity.Category]struct{}, p.cfg.MaxSize)
+	var nodes []crawledCategory
+	queue := make([]crawledCategory, 0, len(roots))
+
+	for _, root := range roots {
+		if _, ok := visited[root]; ok {
+			continue
+		}
+		visited[root] = struct{}{}
+		queue = append(queue, crawledCategory{category: root, depth: 0, weight: 1})
+	}
+
+	for len(queue) > 0 && len(nodes) < p.cfg.MaxSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		node := queue[0]
+		queue = queue[1:]
+		nodes = append(nodes, node)
+
+		if node.depth >= p.cfg.MaxDepth {
+			continue
+		}
+
+		subs, err := p.wikiClient.GetSubcategories(ctx, node.category, p.cfg.SubcatLimit)
+		if err != nil {
+			p.logger.Warn("Failed to get subcategories",
+				zap.String("category", string(node.category)), zap.Error(err))
+			continue
+		}
+
+		for _, sub := range subs {
+			if _, ok := visited[sub]; ok {
+				continue
+			}
+			visited[sub] = struct{}{}
+			queue = append(queue, crawledCategory{
+				category: sub,
+				depth:    node.depth + 1,
+				weight:   math.Pow(p.cfg.DepthDecay, float64(node.depth+1)),
+			})
+		}
+	}
+
+	p.setNodes(nodes)
+
+	p.mu.Lock()
+	p.crawledAt = time.Now()
+	p.mu.Unlock()
+
+	p.logger.Info("Category tree crawled", zap.Int("categories", len(nodes)))
+	return nil
+}
+
+// GetCategory возвращает случайную категорию с учётом веса.
+func (p *CrawlerProvider) GetCategory(_ context.Context) (entity.Category, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if len(p.nodes) == 0 {
+		return "", entity.ErrCategoryNotFound
+	}
+
+	total := p.cumWeights[len(p.cumWeights)-1]
+	idx := sort.SearchFloat64s(p.cumWeights, rand.Float64()*total)
+	if idx >= len(p.nodes) {
+		idx = len(p.nodes) - 1
+	}
+	return p.nodes[idx].category, nil
+}
+
+// GetCategories возвращает все категории закэшированного дерева.
+func (p *CrawlerProvider) GetCategories(_ context.Context) ([]entity.Category, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	categories := make([]entity.Category, len(p.nodes))
+	for i, n := range p.nodes {
+		categories[i] = n.category
+	}
+	return categories, nil
+}
+
+// SetCategories заменяет корни обхода; дерево пересобирается при следующем Refresh.
+func (p *CrawlerProvider) SetCategories(_ context.Context, categories []entity.Category) error {
+	p.mu.Lock()
+	p.roots = append([]entity.Category(nil), categories...)
+	p.mu.Unlock()
+
+	p.setNodes(p.rootNodes())
+	return nil
+}
+
+// AddCategory добавляет новый корень обхода и сразу делает его доступным для выбора.
+func (p *CrawlerProvider) AddCategory(_ context.Context, category entity.Category) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, r := range p.roots {
+		if r == category {
+			return nil
+		}
+	}
+	p.roots = append(p.roots, category)
+
+	for _, n := range p.nodes {
+		if n.category == category {
+			return nil
+		}
+	}
+	p.nodes = append(p.nodes, crawledCategory{category: category, depth: 0, weight: 1})
+	p.cumWeights = cumulativeWeights(p.nodes)
+	return nil
+}
+
+// RemoveCategory убирает категорию из корней и из закэшированного дерева.
+func (p *CrawlerProvider) RemoveCategory(_ context.Context, category entity.Category) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.roots = removeCategory(p.roots, category)
+
+	nodes := make([]crawledCategory, 0, len(p.nodes))
+	for _, n := range p.nodes {
+		if n.category != category {
+			nodes = append(nodes, n)
+		}
+	}
+	p.nodes = nodes
+	p.cumWeights = cumulativeWeights(nodes)
+	return nil
+}
+
+// CrawledAt возвращает время последнего успешного обхода.
+func (p *CrawlerProvider) CrawledAt() time.Time {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.crawledAt
+}
+
+func (p *CrawlerProvider) rootNodes() []crawledCategory {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	nodes := make([]crawledCategory, 0, len(p.roots))
+	for _, r := range p.roots {
+		nodes = append(nodes, crawledCategory{category: r, depth: 0, weight: 1})
+	}
+	return nodes
+}
+
+func (p *CrawlerProvider) setNodes(nodes []crawledCategory) {
+	cum := cumulativeWeights(nodes)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.nodes = nodes
+	p.cumWeights = cum
+}
+
+func cumulativeWeights(nodes []crawledCategory) []float64 {
+	cum := make([]float64, len(nodes))
+	var total float64
+	for i, n := range nodes {
+		total += n.weight
+		cum[i] = total
+	}
+	return cum
+}
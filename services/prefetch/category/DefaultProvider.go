@@ -5,16 +5,19 @@ import (
 	"fmt"
 	"github.com/NordCoder/Story/internal/entity"
 	"math/rand"
+	"sync"
 )
 
 type DefaultProvider struct {
+	// mu защищает categories: админка меняет их, пока префетчер выбирает категорию.
+	mu         sync.RWMutex
 	categories []entity.Category
 }
 
 func NewDefaultProvider() *DefaultProvider {
 	return &DefaultProvider{
 		categories: []entity.Category{
-			"Втоарая_мировая_война",
+			"Вторая_мировая_война",
 			"Операции_и_сражения_Второй_мировой_войны",
 			"Участники_Второй_мировой_войны",
 			"Политика_во_Второй_мировой_войне",
@@ -29,6 +32,9 @@ func NewDefaultProvider() *DefaultProvider {
 }
 
 func (p *DefaultProvider) GetCategory(_ context.Context) (entity.Category, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
 	if len(p.categories) == 0 {
 		return "", fmt.Errorf("no categories available")
 	}
@@ -37,13 +43,28 @@ func (p *DefaultProvider) GetCategory(_ context.Context) (entity.Category, error
 }
 
 func (p *DefaultProvider) GetCategories(_ context.Context) ([]entity.Category, error) {
-	return p.categories, nil
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return append([]entity.Category(nil), p.categories...), nil
 }
 
 func (p *DefaultProvider) SetCategories(_ context.Context, categories []entity.Category) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.categories = append([]entity.Category(nil), categories...)
 	return nil
 }
 
 func (p *DefaultProvider) AddCategory(_ context.Context, category entity.Category) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.categories = append(p.categories, category)
+	return nil
+}
+
+func (p *DefaultProvider) RemoveCategory(_ context.Context, category entity.Category) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.categories = removeCategory(p.categories, category)
 	return nil
 }
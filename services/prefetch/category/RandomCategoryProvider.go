@@ -4,12 +4,15 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"sync"
 
 	"github.com/NordCoder/Story/internal/entity"
 )
 
 // RandomCategoryProvider выбирает случайную категорию и язык из списка.
 type RandomCategoryProvider struct {
+	// mu защищает categories: админка меняет их, пока префетчер выбирает категорию.
+	mu         sync.RWMutex
 	categories []entity.Category
 }
 
@@ -20,6 +23,9 @@ func NewRandomCategoryProvider(categories []entity.Category) Provider {
 
 // GetCategory выбирает случайную Selection.
 func (r *RandomCategoryProvider) GetCategory(ctx context.Context) (entity.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	if len(r.categories) == 0 {
 		return "", fmt.Errorf("no categories available")
 	}
@@ -28,15 +34,28 @@ func (r *RandomCategoryProvider) GetCategory(ctx context.Context) (entity.Catego
 }
 
 func (r *RandomCategoryProvider) GetCategories(ctx context.Context) ([]entity.Category, error) {
-	return r.categories, nil
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]entity.Category(nil), r.categories...), nil
 }
 
 func (r *RandomCategoryProvider) SetCategories(ctx context.Context, categories []entity.Category) error {
-	r.categories = categories
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.categories = append([]entity.Category(nil), categories...)
 	return nil
 }
 
 func (r *RandomCategoryProvider) AddCategory(ctx context.Context, category entity.Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.categories = append(r.categories, category)
 	return nil
 }
+
+func (r *RandomCategoryProvider) RemoveCategory(ctx context.Context, category entity.Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.categories = removeCategory(r.categories, category)
+	return nil
+}
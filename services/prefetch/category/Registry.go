@@ -0,0 +1,86 @@
+package category
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/NordCoder/Story/internal/entity"
+	"github.com/NordCoder/Story/internal/logger"
+	"github.com/NordCoder/Story/internal/weighted"
+	"go.uber.org/zap"
+)
+
+var ErrUnknownProvider = errors.New("unknown category provider")
+
+// Registry хранит именованные провайдеры и выбирает между ними по весам.
+// Если выбранный провайдер не смог отдать категорию, пробуются остальные по убыванию веса.
+type Registry struct {
+	providers map[string]Provider
+	chooser   *weighted.Chooser
+}
+
+// NewRegistry создаёт реестр; веса должны ссылаться только на переданные провайдеры.
+func NewRegistry(providers map[string]Provider, weights map[string]float64, opts ...weighted.Option) (*Registry, error) {
+	if err := validateWeights(providers, weights); err != nil {
+		return nil, err
+	}
+	chooser, err := weighted.NewChooser(weights, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Registry{providers: providers, chooser: chooser}, nil
+}
+
+// GetCategory возвращает категорию и имя провайдера, который её отдал.
+func (r *Registry) GetCategory(ctx context.Context) (entity.Category, string, error) {
+	var lastErr error
+	for _, name := range r.chooser.Order() {
+		cat, err := r.providers[name].GetCategory(ctx)
+		if err == nil {
+			return cat, name, nil
+		}
+		logger.LoggerFromContext(ctx).Warn("Failed to get category from provider", zap.String("provider", name), zap.Error(err))
+		lastErr = err
+	}
+	return "", "", fmt.Errorf("all category providers failed: %w", lastErr)
+}
+
+// Get возвращает провайдер по имени.
+func (r *Registry) Get(name string) (Provider, bool) {
+	p, ok := r.providers[name]
+	return p, ok
+}
+
+// Names возвращает имена провайдеров в алфавитном порядке.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Weights возвращает текущие веса провайдеров.
+func (r *Registry) Weights() map[string]float64 {
+	return r.chooser.Weights()
+}
+
+// SetWeights заменяет веса на лету; некорректные веса отклоняются целиком.
+func (r *Registry) SetWeights(weights map[string]float64) error {
+	if err := validateWeights(r.providers, weights); err != nil {
+		return err
+	}
+	return r.chooser.Set(weights)
+}
+
+func validateWeights(providers map[string]Provider, weights map[string]float64) error {
+	for name := range weights {
+		if _, ok := providers[name]; !ok {
+			return fmt.Errorf("%w: %q", ErrUnknownProvider, name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package category
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/NordCoder/Story/internal/entity"
+	"github.com/NordCoder/Story/internal/weighted"
+)
+
+// constRand всегда отдаёт одно и то же число.
+type constRand float64
+
+func (r constRand) Float64() float64 { return float64(r) }
+
+func TestRegistryGetCategoryIsDeterministicWithSeed(t *testing.T) {
+	newRegistry := func() *Registry {
+		r, err := NewRegistry(map[string]Provider{
+			"first":  NewRandomCategoryProvider([]entity.Category{"a"}),
+			"second": NewRandomCategoryProvider([]entity.Category{"b"}),
+		}, map[string]float64{"first": 1, "second": 1}, weighted.WithRand(weighted.NewSeededRand(1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	r1, r2 := newRegistry(), newRegistry()
+
+	ctx := context.Background()
+	for i := 0; i < 50; i++ {
+		_, p1, err1 := r1.GetCategory(ctx)
+		_, p2, err2 := r2.GetCategory(ctx)
+		if err1 != nil || err2 != nil {
+			t.Fatalf("GetCategory errors: %v, %v", err1, err2)
+		}
+		if p1 != p2 {
+			t.Fatalf("call %d: provider differs for the same seed: %q vs %q", i, p1, p2)
+		}
+	}
+}
+
+func TestRegistryFallsBackToNextProvider(t *testing.T) {
+	// 0 выбирает "empty" (первый по алфавиту), он пуст — берётся следующий по весу
+	r, err := NewRegistry(map[string]Provider{
+		"empty": NewStackProvider(),
+		"light": NewRandomCategoryProvider([]entity.Category{"light"}),
+		"heavy": NewRandomCategoryProvider([]entity.Category{"heavy"}),
+	}, map[string]float64{"empty": 1, "light": 1, "heavy": 5}, weighted.WithRand(constRand(0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cat, provider, err := r.GetCategory(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if provider != "heavy" || cat != "heavy" {
+		t.Errorf("GetCategory() = %q from %q, want heavy from heavy", cat, provider)
+	}
+}
+
+func TestRegistryAllProvidersFailed(t *testing.T) {
+	r, err := NewRegistry(map[string]Provider{"empty": NewStackProvider()}, map[string]float64{"empty": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := r.GetCategory(context.Background()); !errors.Is(err, entity.ErrCategoryNotFound) {
+		t.Errorf("GetCategory() error = %v, want wrapped ErrCategoryNotFound", err)
+	}
+}
+
+func TestRegistryRejectsUnknownProviderWeights(t *testing.T) {
+	r, err := NewRegistry(map[string]Provider{"a": NewStackProvider()}, map[string]float64{"a": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.SetWeights(map[string]float64{"b": 1}); !errors.Is(err, ErrUnknownProvider) {
+		t.Errorf("SetWeights() error = %v, want ErrUnknownProvider", err)
+	}
+}
@@ -2,10 +2,13 @@ package category
 
 import (
 	"context"
+	"sync"
+
 	"github.com/NordCoder/Story/internal/entity"
 )
 
 type StackProvider struct {
+	mu         sync.Mutex
 	categories []entity.Category
 }
 
@@ -16,6 +19,9 @@ func NewStackProvider() *StackProvider {
 }
 
 func (s *StackProvider) GetCategory(_ context.Context) (entity.Category, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	var cat entity.Category
 	if len(s.categories) == 0 {
 		return cat, entity.ErrCategoryNotFound
@@ -26,15 +32,28 @@ func (s *StackProvider) GetCategory(_ context.Context) (entity.Category, error)
 }
 
 func (s *StackProvider) GetCategories(_ context.Context) ([]entity.Category, error) {
-	return s.categories, nil
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]entity.Category(nil), s.categories...), nil
 }
 
 func (s *StackProvider) SetCategories(_ context.Context, categories []entity.Category) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.categories = categories
 	return nil
 }
 
 func (s *StackProvider) AddCategory(_ context.Context, category entity.Category) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.categories = append(s.categories, category)
 	return nil
 }
+
+func (s *StackProvider) RemoveCategory(_ context.Context, category entity.Category) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.categories = removeCategory(s.categories, category)
+	return nil
+}
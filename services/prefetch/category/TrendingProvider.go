@@ -0,0 +1,85 @@
+package category
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+
+	"github.com/NordCoder/Story/internal/entity"
+	"github.com/NordCoder/Story/services/prefetch/config"
+)
+
+// ErrReadOnlyProvider возвращается, если набор категорий провайдера нельзя задать вручную.
+var ErrReadOnlyProvider = errors.New("category provider is read-only")
+
+// TrendingSource отдаёт текущие трендовые категории окна по убыванию популярности.
+type TrendingSource interface {
+	TrendingCategories(ctx context.Context, window string, limit int) ([]entity.Category, error)
+}
+
+// TrendingProvider отдаёт случайную категорию из трендов сообщества.
+// Набор вычисляет агрегатор трендов, поэтому добавить категорию вручную нельзя:
+// RemoveCategory исключает категорию из выдачи провайдера, AddCategory снимает исключение.
+type TrendingProvider struct {
+	source TrendingSource
+	cfg    *config.TrendingProviderConfig
+
+	mu       sync.RWMutex
+	excluded map[entity.Category]struct{}
+}
+
+func NewTrendingProvider(source TrendingSource, cfg *config.TrendingProviderConfig) *TrendingProvider {
+	return &TrendingProvider{
+		source:   source,
+		cfg:      cfg,
+		excluded: make(map[entity.Category]struct{}),
+	}
+}
+
+func (p *TrendingProvider) GetCategory(ctx context.Context) (entity.Category, error) {
+	categories, err := p.GetCategories(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(categories) == 0 {
+		return "", entity.ErrCategoryNotFound
+	}
+	return categories[rand.Intn(len(categories))], nil
+}
+
+// GetCategories возвращает тренды окна без исключённых категорий.
+func (p *TrendingProvider) GetCategories(ctx context.Context) ([]entity.Category, error) {
+	trending, err := p.source.TrendingCategories(ctx, p.cfg.Window, p.cfg.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	categories := trending[:0:0]
+	for _, c := range trending {
+		if _, ok := p.excluded[c]; !ok {
+			categories = append(categories, c)
+		}
+	}
+	return categories, nil
+}
+
+func (p *TrendingProvider) SetCategories(_ context.Context, _ []entity.Category) error {
+	return ErrReadOnlyProvider
+}
+
+func (p *TrendingProvider) AddCategory(_ context.Context, category entity.Category) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.excluded, category)
+	return nil
+}
+
+func (p *TrendingProvider) RemoveCategory(_ context.Context, category entity.Category) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.excluded[category] = struct{}{}
+	return nil
+}
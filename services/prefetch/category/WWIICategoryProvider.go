@@ -37,3 +37,7 @@ func (W WWIICategoryProvider) SetCategories(ctx context.Context, category []enti
 func (W WWIICategoryProvider) AddCategory(ctx context.Context, category entity.Category) error {
 	panic("implement me")
 }
+
+func (W WWIICategoryProvider) RemoveCategory(ctx context.Context, category entity.Category) error {
+	return errors.New("nothing to remove, this implementation doesn't support removing")
+}
@@ -1,12 +1,14 @@
 package config
 
 import (
+	"fmt"
 	"time"
 
-	"github.com/spf13/viper"
+	appconfig "github.com/NordCoder/Story/config"
+	"github.com/NordCoder/Story/internal/entity"
 )
 
-const prefetcherConfigPath = "config/prefetch.yaml"
+const PrefetcherConfigPath = "config/prefetch.yaml"
 
 type PrefetcherConfig struct {
 	Enabled         bool          `mapstructure:"enabled"`
@@ -14,19 +16,118 @@ type PrefetcherConfig struct {
 	BatchSize       int           `mapstructure:"batch_size"`
 	MinFacts        int           `mapstructure:"min_facts"`
 	PrefetchOnStart bool          `mapstructure:"prefetch_on_start"`
+
+	// ProviderWeights — веса именованных провайдеров категорий.
+	ProviderWeights map[string]float64 `mapstructure:"provider_weights"`
 }
 
 func NewPrefetcherConfig() (*PrefetcherConfig, error) {
-	viper.SetConfigFile(prefetcherConfigPath)
+	var cfg PrefetcherConfig
+	if err := appconfig.ReadKey(PrefetcherConfigPath, "prefetcher", &cfg); err != nil {
+		return nil, err
+	}
 
-	if err := viper.ReadInConfig(); err != nil {
+	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
 
-	var cfg PrefetcherConfig
-	if err := viper.UnmarshalKey("prefetcher", &cfg); err != nil {
+	return &cfg, nil
+}
+
+func (c *PrefetcherConfig) Validate() error {
+	if c.Interval <= 0 {
+		return fmt.Errorf("prefetcher: interval must be positive, got %s", c.Interval)
+	}
+	if c.BatchSize <= 0 {
+		return fmt.Errorf("prefetcher: batch_size must be positive, got %d", c.BatchSize)
+	}
+	if c.MinFacts < 0 {
+		return fmt.Errorf("prefetcher: min_facts must not be negative, got %d", c.MinFacts)
+	}
+	return nil
+}
+
+// CrawlerConfig настраивает обход дерева подкатегорий Википедии.
+type CrawlerConfig struct {
+	Roots           []entity.Category `mapstructure:"roots"`
+	MaxDepth        int               `mapstructure:"max_depth"`
+	MaxSize         int               `mapstructure:"max_size"`
+	SubcatLimit     int               `mapstructure:"subcategory_limit"`
+	DepthDecay      float64           `mapstructure:"depth_decay"`
+	RefreshInterval time.Duration     `mapstructure:"refresh_interval"`
+}
+
+func NewCrawlerConfig() (*CrawlerConfig, error) {
+	var cfg CrawlerConfig
+	if err := appconfig.ReadKey(PrefetcherConfigPath, "crawler", &cfg); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+func (c *CrawlerConfig) Validate() error {
+	if len(c.Roots) == 0 {
+		return fmt.Errorf("crawler: at least one root category is required")
+	}
+	if c.RefreshInterval <= 0 {
+		return fmt.Errorf("crawler: refresh_interval must be positive, got %s", c.RefreshInterval)
+	}
+	if c.MaxSize <= 0 {
+		return fmt.Errorf("crawler: max_size must be positive, got %d", c.MaxSize)
+	}
+	if c.MaxDepth < 0 {
+		return fmt.Errorf("crawler: max_depth must not be negative, got %d", c.MaxDepth)
+	}
+	if c.MaxDepth > 0 && c.SubcatLimit <= 0 {
+		return fmt.Errorf("crawler: subcategory_limit must be positive when max_depth > 0, got %d", c.SubcatLimit)
+	}
+	if c.DepthDecay <= 0 || c.DepthDecay > 1 {
+		return fmt.Errorf("crawler: depth_decay must be in (0, 1], got %v", c.DepthDecay)
+	}
+	return nil
+}
+
+// TrendingProviderConfig настраивает провайдер трендовых категорий.
+type TrendingProviderConfig struct {
+	// Window — окно трендов из trending.windows в recommendation.yaml.
+	Window string `mapstructure:"window"`
+	// Limit — из скольких лучших категорий окна выбирается случайная.
+	Limit int `mapstructure:"limit"`
+}
+
+func NewTrendingProviderConfig() (*TrendingProviderConfig, error) {
+	var cfg TrendingProviderConfig
+	if err := appconfig.ReadKey(PrefetcherConfigPath, "trending", &cfg); err != nil {
 		return nil, err
 	}
 
+	if cfg.Window == "" || cfg.Limit <= 0 {
+		return nil, fmt.Errorf("trending: window is required and limit must be positive")
+	}
+
+	return &cfg, nil
+}
+
+// BlocklistConfig настраивает синхронизацию заблокированных категорий между репликами.
+type BlocklistConfig struct {
+	// SyncInterval — как часто перечитывать блокировки из Redis.
+	SyncInterval time.Duration `mapstructure:"sync_interval"`
+}
+
+func NewBlocklistConfig() (*BlocklistConfig, error) {
+	var cfg BlocklistConfig
+	if err := appconfig.ReadKey(PrefetcherConfigPath, "blocklist", &cfg); err != nil {
+		return nil, err
+	}
+
+	if cfg.SyncInterval <= 0 {
+		return nil, fmt.Errorf("blocklist: sync_interval must be positive, got %s", cfg.SyncInterval)
+	}
+
 	return &cfg, nil
 }
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NordCoder/Story/internal/entity"
+)
+
+func TestCrawlerConfigValidate(t *testing.T) {
+	valid := func() CrawlerConfig {
+		return CrawlerConfig{
+			Roots:           []entity.Category{"История"},
+			MaxDepth:        2,
+			MaxSize:         300,
+			SubcatLimit:     50,
+			DepthDecay:      0.5,
+			RefreshInterval: 6 * time.Hour,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(c *CrawlerConfig)
+		wantErr bool
+	}{
+		{name: "valid", mutate: func(*CrawlerConfig) {}},
+		{name: "roots only", mutate: func(c *CrawlerConfig) { c.MaxDepth, c.SubcatLimit = 0, 0 }},
+		{name: "decay one", mutate: func(c *CrawlerConfig) { c.DepthDecay = 1 }},
+		{name: "no roots", mutate: func(c *CrawlerConfig) { c.Roots = nil }, wantErr: true},
+		{name: "zero refresh interval", mutate: func(c *CrawlerConfig) { c.RefreshInterval = 0 }, wantErr: true},
+		{name: "negative refresh interval", mutate: func(c *CrawlerConfig) { c.RefreshInterval = -time.Minute }, wantErr: true},
+		{name: "zero max size", mutate: func(c *CrawlerConfig) { c.MaxSize = 0 }, wantErr: true},
+		{name: "negative max depth", mutate: func(c *CrawlerConfig) { c.MaxDepth = -1 }, wantErr: true},
+		{name: "no subcategory limit", mutate: func(c *CrawlerConfig) { c.SubcatLimit = 0 }, wantErr: true},
+		{name: "zero decay", mutate: func(c *CrawlerConfig) { c.DepthDecay = 0 }, wantErr: true},
+		{name: "decay above one", mutate: func(c *CrawlerConfig) { c.DepthDecay = 1.5 }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.mutate(&cfg)
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
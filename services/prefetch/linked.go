@@ -0,0 +1,42 @@
+package prefetch
+
+import (
+	"sync"
+
+	"github.com/NordCoder/Story/internal/entity"
+)
+
+// maxLinkedSources — сколько исходных статей помнит linkedCache; при переполнении он сбрасывается.
+const maxLinkedSources = 10000
+
+// linkedCache запоминает по source_url исходной статьи ID фактов, загруженных по её ссылкам.
+// Повторный запрос похожих к той же статье берёт их из репозитория, а не сохраняет статьи
+// заново под новыми ID.
+type linkedCache struct {
+	mu  sync.Mutex
+	ids map[string][]entity.FactID
+}
+
+func newLinkedCache() *linkedCache {
+	return &linkedCache{ids: make(map[string][]entity.FactID)}
+}
+
+func (c *linkedCache) get(sourceURL string) []entity.FactID {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ids[sourceURL]
+}
+
+func (c *linkedCache) remember(sourceURL string, facts []*entity.Fact) {
+	ids := make([]entity.FactID, len(facts))
+	for i, f := range facts {
+		ids[i] = f.ID
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.ids[sourceURL]; !ok && len(c.ids) >= maxLinkedSources {
+		c.ids = make(map[string][]entity.FactID)
+	}
+	c.ids[sourceURL] = ids
+}
@@ -2,67 +2,134 @@ package prefetch
 
 import (
 	"context"
+	"errors"
 	"github.com/NordCoder/Story/internal/entity"
-	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/NordCoder/Story/services/prefetch/category"
 	"github.com/NordCoder/Story/services/prefetch/config"
 
-	"github.com/NordCoder/Story/internal/infrastructure/redis"
+	"github.com/NordCoder/Story/internal/infrastructure"
 	"github.com/NordCoder/Story/internal/infrastructure/wikipedia"
 	"go.uber.org/zap"
 )
 
 type Prefetcher interface {
 	Run(ctx context.Context) error
+	// SetConfig применяет новую конфигурацию на лету (интервал, размер батча, min_facts, enabled).
+	SetConfig(cfg *config.PrefetcherConfig) error
+	// PrefetchCategory немедленно загружает факты указанной категории, минуя проверку min_facts.
+	PrefetchCategory(ctx context.Context, concept entity.Category) (Report, error)
+	// Reports возвращает итог последней загрузки по каждой категории.
+	Reports() []Report
+	// FetchLinked загружает и сохраняет до limit статей, на которые ссылается статья факта.
+	FetchLinked(ctx context.Context, source *entity.Fact, limit int) ([]*entity.Fact, error)
+}
+
+// Report — итог одной загрузки фактов по категории.
+type Report struct {
+	Category  entity.Category
+	Saved     int // сохранено в репозиторий
+	Rejected  int // отброшено валидацией префетчера
+	Failed    int // не удалось сохранить
+	Duplicate int // статья уже в репозитории под тем же ID: новых фактов категория не дала
+	Warmed    int // восстановлено из архива, когда Википедия недоступна
+	Err       error
+	StartedAt time.Time
+	Duration  time.Duration
 }
 
 type prefetcher struct {
-	cfg                      *config.PrefetcherConfig
-	wikipediaClient          wikipedia.WikiClient
-	factRepo                 *redis.FactRepository
-	logger                   *zap.Logger
-	basicCategoryProvider    category.Provider
-	advancedCategoryProvider category.Provider
+	cfg             atomic.Pointer[config.PrefetcherConfig]
+	cfgUpdated      chan struct{}
+	wikipediaClient wikipedia.WikiClient
+	factRepo        infrastructure.FactRepository
+	logger          *zap.Logger
+	providers       *category.Registry
+	blocklist       *category.Blocklist
+	archive         infrastructure.FactArchive
+	linked          *linkedCache
+
+	reportsMu sync.RWMutex
+	reports   map[entity.Category]Report
+}
+
+type Option func(*prefetcher)
+
+// WithBlocklist запрещает загрузку категорий из списка.
+func WithBlocklist(b *category.Blocklist) Option {
+	return func(p *prefetcher) { p.blocklist = b }
+}
+
+// WithArchive сохраняет каждый факт ещё и в архив, а при недоступности Википедии
+// прогревает репозиторий фактами категории из архива.
+func WithArchive(a infrastructure.FactArchive) Option {
+	return func(p *prefetcher) { p.archive = a }
 }
 
 // NewPrefetcher создаёт новый экземпляр префетчера.
 func NewPrefetcher(
 	cfg *config.PrefetcherConfig,
 	wikipediaClient wikipedia.WikiClient,
-	factRepo *redis.FactRepository,
+	factRepo infrastructure.FactRepository,
 	logger *zap.Logger,
-	basicCategoryProvider category.Provider,
-	advancedCategoryProvider category.Provider,
+	providers *category.Registry,
+	opts ...Option,
 ) Prefetcher {
-	return &prefetcher{
-		cfg:                      cfg,
-		wikipediaClient:          wikipediaClient,
-		factRepo:                 factRepo,
-		logger:                   logger,
-		basicCategoryProvider:    basicCategoryProvider,
-		advancedCategoryProvider: advancedCategoryProvider,
+	p := &prefetcher{
+		cfgUpdated:      make(chan struct{}, 1),
+		wikipediaClient: wikipediaClient,
+		factRepo:        factRepo,
+		logger:          logger,
+		providers:       providers,
+		blocklist:       category.NewBlocklist(),
+		linked:          newLinkedCache(),
+		reports:         make(map[entity.Category]Report),
+	}
+	p.cfg.Store(cfg)
+	for _, o := range opts {
+		o(p)
 	}
+	return p
+}
+
+func (p *prefetcher) SetConfig(cfg *config.PrefetcherConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	p.cfg.Store(cfg)
+
+	// будим Run, чтобы он перезапустил тикер с новым интервалом
+	select {
+	case p.cfgUpdated <- struct{}{}:
+	default:
+	}
+	return nil
 }
 
 // Run запускает префетчер.
+// Пока префетчер выключен в конфиге, тикер продолжает идти, но итерации пропускаются:
+// так его можно включить без перезапуска процесса.
 func (p *prefetcher) Run(ctx context.Context) error {
-	if !p.cfg.Enabled {
+	cfg := p.cfg.Load()
+	if !cfg.Enabled {
 		p.logger.Info("Prefetcher is disabled")
-		return nil
+	} else {
+		p.logger.Info("Prefetcher started")
 	}
 
-	p.logger.Info("Prefetcher started")
-
-	if p.cfg.PrefetchOnStart {
+	if cfg.Enabled && cfg.PrefetchOnStart {
 		p.logger.Info("Prefetching on startup...")
 		if err := p.prefetch(ctx); err != nil {
 			p.logger.Error("Initial prefetch failed", zap.Error(err))
 		}
 	}
 
-	ticker := time.NewTicker(p.cfg.Interval)
+	interval := cfg.Interval
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
@@ -70,7 +137,16 @@ func (p *prefetcher) Run(ctx context.Context) error {
 		case <-ctx.Done():
 			p.logger.Info("Prefetcher stopping gracefully")
 			return ctx.Err()
+		case <-p.cfgUpdated:
+			if next := p.cfg.Load().Interval; next != interval {
+				interval = next
+				ticker.Reset(interval)
+				p.logger.Info("Prefetcher interval changed", zap.Duration("interval", interval))
+			}
 		case <-ticker.C:
+			if !p.cfg.Load().Enabled {
+				continue
+			}
 			if err := p.prefetch(ctx); err != nil {
 				p.logger.Error("Prefetch error", zap.Error(err))
 			}
@@ -86,39 +162,210 @@ func (p *prefetcher) prefetch(ctx context.Context) error {
 		return err
 	}
 
-	if count >= int64(p.cfg.MinFacts) {
+	if count >= int64(p.cfg.Load().MinFacts) {
 		return nil
 	}
 
-	var concept entity.Category
-	r := rand.Float64()
-	if r < 0.7 {
-		concept, err = p.advancedCategoryProvider.GetCategory(ctx)
-	} else {
-		concept, err = p.basicCategoryProvider.GetCategory(ctx)
+	concept, provider, err := p.providers.GetCategory(ctx)
+	if err != nil {
+		p.logger.Error("Failed to get category", zap.Error(err))
+		return err
 	}
 
-	if err != nil {
-		p.logger.Warn("Failed to get category from recommendations", zap.Error(err))
-		concept, err = p.basicCategoryProvider.GetCategory(ctx)
+	if p.blocklist.IsBlocked(concept) {
+		p.logger.Info("Skipping blocklisted category", zap.String("category", string(concept)), zap.String("provider", provider))
+		return nil
 	}
 
-	summaries, err := p.wikipediaClient.GetCategorySummaries(ctx, concept, p.cfg.BatchSize)
+	p.logger.Info("Prefetching category", zap.String("category", string(concept)), zap.String("provider", provider))
+
+	_, err = p.PrefetchCategory(ctx, concept)
+	return err
+}
+
+// PrefetchCategory загружает до batch_size фактов категории и запоминает итог.
+func (p *prefetcher) PrefetchCategory(ctx context.Context, concept entity.Category) (Report, error) {
+	if p.blocklist.IsBlocked(concept) {
+		return Report{}, entity.ErrCategoryBlocked
+	}
+
+	report := Report{Category: concept, StartedAt: time.Now()}
+
+	summaries, err := p.wikipediaClient.GetCategorySummaries(ctx, concept, p.cfg.Load().BatchSize)
 	if err != nil {
 		p.logger.Error("Failed to fetch summaries from Wikipedia", zap.Error(err))
-		return err
+		report.Err = err
+		if p.warmFromArchive(ctx, &report) {
+			return p.record(report), nil
+		}
+		return p.record(report), err
 	}
 
 	for _, summary := range summaries {
 		fact := summary.ToFact(concept) // Конвертация ArticleSummary -> Fact
 
-		if err := p.factRepo.Save(ctx, fact); err != nil {
-			p.logger.Warn("Failed to save fact", zap.Error(err))
+		if !isValidFact(fact) {
+			report.Rejected++
 			continue
 		}
 
+		if err := p.store(ctx, fact); err != nil {
+			if errors.Is(err, entity.ErrFactExists) {
+				report.Duplicate++
+			} else {
+				report.Failed++
+			}
+			continue
+		}
+
+		report.Saved++
 		p.logger.Info("Saved fact", zap.String("title", fact.Title))
 	}
 
+	if report.Saved == 0 && report.Duplicate > 0 {
+		p.logger.Info("Category has no new articles", zap.String("category", string(concept)), zap.Int("duplicates", report.Duplicate))
+	}
+	return p.record(report), nil
+}
+
+// FetchLinked загружает статьи, на которые ссылается статья факта. Их настоящая категория
+// неизвестна, поэтому они сохраняются через SaveUnlisted — мимо общей очереди и индекса
+// категории источника — и не пишутся в архив, откуда прогрев разложил бы их по той же
+// категории. Отчёт тоже не записывается: это загрузка по запросу пользователя, а не
+// пополнение категории. Статьи, уже загруженные для того же источника и ещё живые
+// в репозитории, берутся оттуда и не сохраняются повторно.
+func (p *prefetcher) FetchLinked(ctx context.Context, source *entity.Fact, limit int) ([]*entity.Fact, error) {
+	if p.blocklist.IsBlocked(source.Category) {
+		return nil, entity.ErrCategoryBlocked
+	}
+
+	facts := p.knownLinked(ctx, source)
+	if len(facts) >= limit {
+		return facts[:limit], nil
+	}
+
+	summaries, err := p.wikipediaClient.GetLinkedSummaries(ctx, source.Title, source.Category, limit)
+	if err != nil {
+		return facts, err
+	}
+
+	known := make(map[string]struct{}, len(facts))
+	for _, f := range facts {
+		known[f.SourceURL] = struct{}{}
+	}
+	for _, summary := range summaries {
+		if len(facts) >= limit {
+			break
+		}
+		if _, ok := known[summary.PageURL]; ok {
+			continue
+		}
+		fact := summary.ToFact(source.Category)
+		if !isValidFact(fact) {
+			continue
+		}
+		if err := p.factRepo.SaveUnlisted(ctx, fact); err != nil && !errors.Is(err, entity.ErrFactExists) {
+			p.logger.Warn("Failed to save linked fact", zap.Error(err))
+			continue
+		}
+		known[fact.SourceURL] = struct{}{}
+		facts = append(facts, fact)
+	}
+
+	p.linked.remember(source.SourceURL, facts)
+	return facts, nil
+}
+
+// knownLinked возвращает ещё живые факты, загруженные раньше по ссылкам статьи source.
+func (p *prefetcher) knownLinked(ctx context.Context, source *entity.Fact) []*entity.Fact {
+	ids := p.linked.get(source.SourceURL)
+	if len(ids) == 0 {
+		return nil
+	}
+	facts, err := p.factRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		p.logger.Warn("Failed to read linked facts", zap.Error(err))
+		return nil
+	}
+	return facts
+}
+
+// store сохраняет факт в репозиторий и, если он подключён, в архив. Архив пишется первым:
+// статья, которая уже есть в архиве, сохраняется под прежним ID, а не под новым из ToFact.
+// Ошибка архива не считается ошибкой сохранения: факт всё равно доступен пользователям.
+func (p *prefetcher) store(ctx context.Context, fact *entity.Fact) error {
+	if p.archive != nil {
+		id, err := p.archive.Archive(ctx, fact)
+		if err != nil {
+			p.logger.Warn("Failed to archive fact", zap.Error(err))
+		} else {
+			fact.ID = id
+		}
+	}
+	if err := p.factRepo.Save(ctx, fact); err != nil {
+		if !errors.Is(err, entity.ErrFactExists) {
+			p.logger.Warn("Failed to save fact", zap.Error(err))
+		}
+		return err
+	}
 	return nil
 }
+
+// warmFromArchive переносит в репозиторий до batch_size фактов категории из архива.
+// Возвращает true, если удалось восстановить хотя бы один факт.
+func (p *prefetcher) warmFromArchive(ctx context.Context, report *Report) bool {
+	if p.archive == nil {
+		return false
+	}
+
+	facts, err := p.archive.GetByCategory(ctx, report.Category, p.cfg.Load().BatchSize)
+	if err != nil {
+		p.logger.Error("Failed to read facts from archive", zap.Error(err))
+		return false
+	}
+
+	for _, fact := range facts {
+		if err := p.factRepo.Save(ctx, fact); err != nil {
+			if errors.Is(err, entity.ErrFactExists) {
+				report.Duplicate++
+				continue
+			}
+			p.logger.Warn("Failed to save archived fact", zap.Error(err))
+			report.Failed++
+			continue
+		}
+		report.Warmed++
+	}
+
+	p.logger.Info("Warmed facts from archive", zap.String("category", string(report.Category)), zap.Int("warmed", report.Warmed))
+	return report.Warmed > 0
+}
+
+// record дописывает длительность загрузки и сохраняет отчёт как последний по категории.
+func (p *prefetcher) record(report Report) Report {
+	report.Duration = time.Since(report.StartedAt)
+
+	p.reportsMu.Lock()
+	p.reports[report.Category] = report
+	p.reportsMu.Unlock()
+
+	return report
+}
+
+// Reports возвращает итоги последних загрузок, отсортированные по категории.
+func (p *prefetcher) Reports() []Report {
+	p.reportsMu.RLock()
+	defer p.reportsMu.RUnlock()
+
+	reports := make([]Report, 0, len(p.reports))
+	for _, r := range p.reports {
+		reports = append(reports, r)
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Category < reports[j].Category })
+	return reports
+}
+
+// isValidFact отбрасывает факты, которые нельзя показать пользователю.
+func isValidFact(f *entity.Fact) bool {
+	return f.Title != "" && f.Summary != "" && f.SourceURL != ""
+}
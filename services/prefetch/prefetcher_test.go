@@ -0,0 +1,146 @@
+package prefetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/NordCoder/Story/internal/entity"
+	"github.com/NordCoder/Story/internal/infrastructure"
+	"github.com/NordCoder/Story/internal/infrastructure/memory"
+	"github.com/NordCoder/Story/internal/infrastructure/wikipedia"
+	"github.com/NordCoder/Story/services/prefetch/config"
+	"go.uber.org/zap"
+)
+
+// linkedWiki отвечает на GetLinkedSummaries одними и теми же статьями и считает вызовы.
+type linkedWiki struct {
+	wikipedia.WikiClient
+	calls int
+}
+
+func (w *linkedWiki) GetLinkedSummaries(_ context.Context, _ string, category entity.Category, limit int) ([]*wikipedia.ArticleSummary, error) {
+	w.calls++
+	summaries := make([]*wikipedia.ArticleSummary, limit)
+	for i := range summaries {
+		summaries[i] = &wikipedia.ArticleSummary{
+			Title:    fmt.Sprintf("Linked %d", i),
+			Category: category,
+			Extract:  "extract",
+			PageURL:  fmt.Sprintf("https://ru.wikipedia.org/wiki/Linked_%d", i),
+			Lang:     "ru",
+		}
+	}
+	return summaries, nil
+}
+
+func (w *linkedWiki) GetCategorySummaries(ctx context.Context, category entity.Category, limit int) ([]*wikipedia.ArticleSummary, error) {
+	return w.GetLinkedSummaries(ctx, "", category, limit)
+}
+
+// urlArchive, как и архив в Postgres, выдаёт один ID на source_url.
+type urlArchive struct {
+	infrastructure.FactArchive
+	ids map[string]entity.FactID
+}
+
+func (a *urlArchive) Archive(_ context.Context, f *entity.Fact) (entity.FactID, error) {
+	if id, ok := a.ids[f.SourceURL]; ok {
+		return id, nil
+	}
+	a.ids[f.SourceURL] = f.ID
+	return f.ID, nil
+}
+
+func TestPrefetchCategoryCountsDuplicates(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewFactRepository(time.Hour)
+	cfg := &config.PrefetcherConfig{Interval: time.Minute, BatchSize: 3}
+	p := NewPrefetcher(cfg, &linkedWiki{}, repo, zap.NewNop(), nil, WithArchive(&urlArchive{ids: map[string]entity.FactID{}}))
+
+	first, err := p.PrefetchCategory(ctx, "История")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// та же выдача Википедии: статьи уже в архиве и репозитории под прежними ID
+	second, err := p.PrefetchCategory(ctx, "История")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first.Saved != 3 || first.Duplicate != 0 {
+		t.Errorf("first report: saved %d, duplicate %d; want 3, 0", first.Saved, first.Duplicate)
+	}
+	if second.Saved != 0 || second.Duplicate != 3 || second.Failed != 0 {
+		t.Errorf("second report: saved %d, duplicate %d, failed %d; want 0, 3, 0", second.Saved, second.Duplicate, second.Failed)
+	}
+	if n, _ := repo.CountFacts(ctx); n != 3 {
+		t.Errorf("repository holds %d facts, want 3", n)
+	}
+}
+
+func newLinkedPrefetcher(wiki wikipedia.WikiClient) (*prefetcher, *memory.FactRepository) {
+	repo := memory.NewFactRepository(time.Hour)
+	cfg := &config.PrefetcherConfig{Interval: time.Minute, BatchSize: 10}
+	return NewPrefetcher(cfg, wiki, repo, zap.NewNop(), nil).(*prefetcher), repo
+}
+
+func TestFetchLinkedReusesStoredFacts(t *testing.T) {
+	ctx := context.Background()
+	wiki := &linkedWiki{}
+	p, repo := newLinkedPrefetcher(wiki)
+	source := &entity.Fact{ID: entity.NewFactID(), Title: "Source", Category: "История", SourceURL: "https://ru.wikipedia.org/wiki/Source"}
+
+	first, err := p.FetchLinked(ctx, source, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := p.FetchLinked(ctx, source, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if wiki.calls != 1 {
+		t.Errorf("wikipedia called %d times, want 1", wiki.calls)
+	}
+	for i := range first {
+		if first[i].ID != second[i].ID {
+			t.Errorf("fact %d: ID changed from %s to %s", i, first[i].ID, second[i].ID)
+		}
+	}
+	if n, _ := repo.CountFacts(ctx); n != 0 {
+		t.Errorf("feed queue holds %d facts, want 0: linked facts must not be enqueued", n)
+	}
+	if _, err := repo.GetByCategory(ctx, source.Category, 10); !errors.Is(err, entity.ErrCategoryNotFound) {
+		t.Errorf("GetByCategory(%q) error = %v, want ErrCategoryNotFound: linked facts were indexed under the source category", source.Category, err)
+	}
+}
+
+func TestFetchLinkedStoresOnlyNewArticles(t *testing.T) {
+	ctx := context.Background()
+	wiki := &linkedWiki{}
+	p, repo := newLinkedPrefetcher(wiki)
+	source := &entity.Fact{ID: entity.NewFactID(), Title: "Source", Category: "История", SourceURL: "https://ru.wikipedia.org/wiki/Source"}
+
+	first, err := p.FetchLinked(ctx, source, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	more, err := p.FetchLinked(ctx, source, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(more) != 4 || more[0].ID != first[0].ID || more[1].ID != first[1].ID {
+		t.Errorf("FetchLinked() = %v, want the first two facts followed by two new ones", more)
+	}
+	ids := make([]entity.FactID, len(more))
+	for i, f := range more {
+		ids[i] = f.ID
+	}
+	if stored, _ := repo.GetByIDs(ctx, ids); len(stored) != 4 {
+		t.Errorf("repository holds %d of the linked facts, want 4", len(stored))
+	}
+}
@@ -0,0 +1,92 @@
+package bandit
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/NordCoder/Story/internal/weighted"
+)
+
+// Arm — апостериорное Beta(Alpha, Beta) распределение вероятности того, что показ категории понравится.
+type Arm struct {
+	Alpha float64
+	Beta  float64
+}
+
+// Thompson выбирает ручку сэмплированием Томпсона: из распределения каждой ручки берётся
+// по одному значению, побеждает наибольшее. Ручки с хорошей историей выигрывают чаще,
+// но ручки с малым числом показов сохраняют шанс за счёт широкого распределения.
+type Thompson struct {
+	rnd weighted.Rand
+}
+
+type globalRand struct{}
+
+func (globalRand) Float64() float64 { return rand.Float64() }
+
+type Option func(*Thompson)
+
+// WithRand подменяет источник случайности, например weighted.NewSeededRand в тестах.
+func WithRand(r weighted.Rand) Option {
+	return func(t *Thompson) { t.rnd = r }
+}
+
+func NewThompson(opts ...Option) *Thompson {
+	t := &Thompson{rnd: globalRand{}}
+	for _, o := range opts {
+		o(t)
+	}
+	return t
+}
+
+// Choose возвращает индекс выбранной ручки или -1 для пустого списка.
+func (t *Thompson) Choose(arms []Arm) int {
+	best, bestSample := -1, math.Inf(-1)
+	for i, a := range arms {
+		if s := t.sampleBeta(a.Alpha, a.Beta); s > bestSample {
+			best, bestSample = i, s
+		}
+	}
+	return best
+}
+
+// sampleBeta: X/(X+Y) для X ~ Gamma(a), Y ~ Gamma(b) распределено как Beta(a, b).
+func (t *Thompson) sampleBeta(a, b float64) float64 {
+	x := t.sampleGamma(a)
+	y := t.sampleGamma(b)
+	if x+y == 0 {
+		return 0
+	}
+	return x / (x + y)
+}
+
+// sampleGamma — метод Марсальи–Цанга; для k < 1 используется Gamma(k+1)·U^(1/k).
+func (t *Thompson) sampleGamma(k float64) float64 {
+	if k < 1 {
+		return t.sampleGamma(k+1) * math.Pow(t.uniform(), 1/k)
+	}
+	d := k - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := t.normal()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := t.uniform()
+		if math.Log(u) < 0.5*x*x+d-d*v+d*math.Log(v) {
+			return d * v
+		}
+	}
+}
+
+// normal — стандартное нормальное значение по Боксу–Мюллеру.
+func (t *Thompson) normal() float64 {
+	return math.Sqrt(-2*math.Log(t.uniform())) * math.Cos(2*math.Pi*t.rnd.Float64())
+}
+
+// uniform возвращает значение из (0, 1]: логарифм от нуля не определён.
+func (t *Thompson) uniform() float64 {
+	return 1 - t.rnd.Float64()
+}
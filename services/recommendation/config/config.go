@@ -0,0 +1,309 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	appconfig "github.com/NordCoder/Story/config"
+	"github.com/NordCoder/Story/internal/entity"
+)
+
+const RecommendationConfigPath = "config/recommendation.yaml"
+
+// EventsConfig настраивает приём неявных сигналов (RecordEvents) и их сброс в Postgres.
+type EventsConfig struct {
+	// QueueSize — ёмкость очереди в памяти; когда она заполнена, RecordEvents отвечает ResourceExhausted.
+	QueueSize int `mapstructure:"queue_size"`
+	// MaxRequestEvents — сколько событий принимается в одном запросе.
+	MaxRequestEvents int           `mapstructure:"max_request_events"`
+	BatchSize        int           `mapstructure:"batch_size"`
+	FlushInterval    time.Duration `mapstructure:"flush_interval"`
+	// RetryBackoff — начальная пауза перед повтором неудачной записи; удваивается до MaxRetryBackoff.
+	RetryBackoff    time.Duration `mapstructure:"retry_backoff"`
+	MaxRetryBackoff time.Duration `mapstructure:"max_retry_backoff"`
+	// SignalWindow — за какой период события учитываются в рекомендациях.
+	SignalWindow time.Duration `mapstructure:"signal_window"`
+}
+
+func NewEventsConfig() (*EventsConfig, error) {
+	var cfg EventsConfig
+	if err := appconfig.ReadKey(RecommendationConfigPath, "events", &cfg); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+func (c *EventsConfig) Validate() error {
+	if c.QueueSize <= 0 || c.BatchSize <= 0 || c.MaxRequestEvents <= 0 {
+		return fmt.Errorf("events: queue_size, batch_size and max_request_events must be positive")
+	}
+	if c.MaxRequestEvents > c.QueueSize {
+		return fmt.Errorf("events: max_request_events (%d) must not exceed queue_size (%d)", c.MaxRequestEvents, c.QueueSize)
+	}
+	if c.FlushInterval <= 0 || c.RetryBackoff <= 0 || c.MaxRetryBackoff < c.RetryBackoff {
+		return fmt.Errorf("events: flush_interval and retry_backoff must be positive, max_retry_backoff >= retry_backoff")
+	}
+	if c.SignalWindow <= 0 {
+		return fmt.Errorf("events: signal_window must be positive, got %s", c.SignalWindow)
+	}
+	return nil
+}
+
+// PropagationConfig настраивает распространение лайка категории на её подкатегории.
+type PropagationConfig struct {
+	WorkerCount int `mapstructure:"worker_count"`
+	QueueSize   int `mapstructure:"queue_size"`
+	// MaxDepth — на сколько уровней вниз от лайкнутой категории распространяется вес.
+	MaxDepth    int `mapstructure:"max_depth"`
+	SubcatLimit int `mapstructure:"subcategory_limit"`
+	// DecayFactor — подкатегория на глубине d получает decay_factor^d.
+	DecayFactor float64 `mapstructure:"decay_factor"`
+}
+
+func NewPropagationConfig() (*PropagationConfig, error) {
+	var cfg PropagationConfig
+	if err := appconfig.ReadKey(RecommendationConfigPath, "propagation", &cfg); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+func (c *PropagationConfig) Validate() error {
+	if c.WorkerCount <= 0 || c.QueueSize <= 0 || c.SubcatLimit <= 0 {
+		return fmt.Errorf("propagation: worker_count, queue_size and subcategory_limit must be positive")
+	}
+	if c.MaxDepth < 0 {
+		return fmt.Errorf("propagation: max_depth must not be negative, got %d", c.MaxDepth)
+	}
+	if c.DecayFactor <= 0 || c.DecayFactor > 1 {
+		return fmt.Errorf("propagation: decay_factor must be in (0, 1], got %v", c.DecayFactor)
+	}
+	return nil
+}
+
+// PreferencesConfig настраивает хранение интересов пользователя по категориям.
+type PreferencesConfig struct {
+	// HalfLife — за сколько скор категории без новых сигналов убывает вдвое; 0 выключает затухание.
+	HalfLife time.Duration `mapstructure:"half_life"`
+}
+
+func NewPreferencesConfig() (*PreferencesConfig, error) {
+	var cfg PreferencesConfig
+	if err := appconfig.ReadKey(RecommendationConfigPath, "preferences", &cfg); err != nil {
+		return nil, err
+	}
+
+	if cfg.HalfLife < 0 {
+		return nil, fmt.Errorf("preferences: half_life must not be negative, got %s", cfg.HalfLife)
+	}
+
+	return &cfg, nil
+}
+
+// ColdStartConfig настраивает добор рекомендаций, когда у пользователя мало собственных данных.
+type ColdStartConfig struct {
+	// Size — сколько категорий отдаёт GetUserRec.
+	Size int `mapstructure:"size"`
+	// Onboarding — стартовый набор категорий, которым добирается выдача в последнюю очередь.
+	Onboarding []entity.Category `mapstructure:"onboarding"`
+}
+
+func NewColdStartConfig() (*ColdStartConfig, error) {
+	var cfg ColdStartConfig
+	if err := appconfig.ReadKey(RecommendationConfigPath, "cold_start", &cfg); err != nil {
+		return nil, err
+	}
+
+	if cfg.Size <= 0 {
+		return nil, fmt.Errorf("cold_start: size must be positive, got %d", cfg.Size)
+	}
+
+	return &cfg, nil
+}
+
+// InterestsConfig описывает каталог тем для онбординга (ListInterestTopics / SetInterests).
+type InterestsConfig struct {
+	// InitialWeight — скор, с которым выбранная тема попадает в интересы пользователя.
+	InitialWeight float64 `mapstructure:"initial_weight"`
+	// DefaultLang — язык названий, если у темы нет перевода на запрошенный.
+	DefaultLang string `mapstructure:"default_lang"`
+	// SubcatLimit — сколько подкатегорий Википедии показывать под каждой темой.
+	SubcatLimit     int             `mapstructure:"subcategory_limit"`
+	RefreshInterval time.Duration   `mapstructure:"refresh_interval"`
+	Topics          []InterestTopic `mapstructure:"topics"`
+}
+
+type InterestTopic struct {
+	Category entity.Category `mapstructure:"category"`
+	// Titles — название темы по кодам языков.
+	Titles map[string]string `mapstructure:"titles"`
+	// ImageURL — картинка темы; если пусто, берётся миниатюра статьи из категории.
+	ImageURL string `mapstructure:"image_url"`
+}
+
+func NewInterestsConfig() (*InterestsConfig, error) {
+	var cfg InterestsConfig
+	if err := appconfig.ReadKey(RecommendationConfigPath, "interests", &cfg); err != nil {
+		return nil, err
+	}
+
+	if len(cfg.Topics) == 0 {
+		return nil, fmt.Errorf("interests: at least one topic is required")
+	}
+	if cfg.InitialWeight <= 0 {
+		return nil, fmt.Errorf("interests: initial_weight must be positive, got %v", cfg.InitialWeight)
+	}
+	if cfg.SubcatLimit < 0 || cfg.RefreshInterval <= 0 {
+		return nil, fmt.Errorf("interests: subcategory_limit must not be negative and refresh_interval must be positive")
+	}
+
+	return &cfg, nil
+}
+
+// TrendingConfig настраивает агрегатор трендовых категорий по скользящим окнам.
+type TrendingConfig struct {
+	RefreshInterval time.Duration    `mapstructure:"refresh_interval"`
+	Windows         []TrendingWindow `mapstructure:"windows"`
+	// DefaultWindow — окно GetTrending, если клиент его не указал.
+	DefaultWindow string `mapstructure:"default_window"`
+	// TopN — сколько категорий хранится на окно; больше GetTrending не отдаст.
+	TopN    int             `mapstructure:"top_n"`
+	Weights TrendingWeights `mapstructure:"weights"`
+}
+
+type TrendingWindow struct {
+	Name     string        `mapstructure:"name"`
+	Duration time.Duration `mapstructure:"duration"`
+}
+
+// TrendingWeights — вклад каждого сигнала в трендовый скор категории.
+type TrendingWeights struct {
+	// Like — за пользователя с положительным интересом к категории, изменившимся в окне.
+	Like            float64 `mapstructure:"like"`
+	ReactionLike    float64 `mapstructure:"reaction_like"`
+	ReactionDislike float64 `mapstructure:"reaction_dislike"`
+	ReactionSkip    float64 `mapstructure:"reaction_skip"`
+	// Serve — за факт категории, выданный в окне (нужен архив фактов).
+	Serve float64 `mapstructure:"serve"`
+}
+
+func NewTrendingConfig() (*TrendingConfig, error) {
+	var cfg TrendingConfig
+	if err := appconfig.ReadKey(RecommendationConfigPath, "trending", &cfg); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+func (c *TrendingConfig) Validate() error {
+	if c.RefreshInterval <= 0 || c.TopN <= 0 {
+		return fmt.Errorf("trending: refresh_interval and top_n must be positive")
+	}
+	if len(c.Windows) == 0 {
+		return fmt.Errorf("trending: at least one window is required")
+	}
+	seen := make(map[string]struct{}, len(c.Windows))
+	for _, w := range c.Windows {
+		if w.Name == "" || w.Duration <= 0 {
+			return fmt.Errorf("trending: window needs a name and a positive duration, got %q %s", w.Name, w.Duration)
+		}
+		if _, ok := seen[w.Name]; ok {
+			return fmt.Errorf("trending: duplicate window %q", w.Name)
+		}
+		seen[w.Name] = struct{}{}
+	}
+	if _, ok := seen[c.DefaultWindow]; !ok {
+		return fmt.Errorf("trending: default_window %q is not among windows", c.DefaultWindow)
+	}
+	return nil
+}
+
+// Window возвращает окно по имени.
+func (c *TrendingConfig) Window(name string) (TrendingWindow, bool) {
+	for _, w := range c.Windows {
+		if w.Name == name {
+			return w, true
+		}
+	}
+	return TrendingWindow{}, false
+}
+
+// CollaborativeConfig настраивает item-item рекомендации по совместным лайкам.
+type CollaborativeConfig struct {
+	// Share — доля выдачи GetUserRec, отдаваемая похожим категориям; 0 выключает их.
+	Share           float64       `mapstructure:"share"`
+	RefreshInterval time.Duration `mapstructure:"refresh_interval"`
+	// Neighbors — сколько похожих категорий хранить на категорию.
+	Neighbors int `mapstructure:"neighbors"`
+	// MinCoLikes — сколько пользователей должны лайкнуть обе категории, чтобы пара считалась.
+	MinCoLikes int `mapstructure:"min_co_likes"`
+	// MaxUserCategories — сколько лучших категорий пользователя участвуют в расчёте.
+	MaxUserCategories int `mapstructure:"max_user_categories"`
+}
+
+func NewCollaborativeConfig() (*CollaborativeConfig, error) {
+	var cfg CollaborativeConfig
+	if err := appconfig.ReadKey(RecommendationConfigPath, "collaborative", &cfg); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+func (c *CollaborativeConfig) Validate() error {
+	if c.Share < 0 || c.Share > 1 {
+		return fmt.Errorf("collaborative: share must be in [0, 1], got %v", c.Share)
+	}
+	if c.RefreshInterval <= 0 || c.Neighbors <= 0 || c.MinCoLikes <= 0 || c.MaxUserCategories <= 0 {
+		return fmt.Errorf("collaborative: refresh_interval, neighbors, min_co_likes and max_user_categories must be positive")
+	}
+	return nil
+}
+
+// BanditConfig настраивает выбор категории для GetFact сэмплированием Томпсона.
+type BanditConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// PriorAlpha и PriorBeta — априорное Beta-распределение категории без истории.
+	PriorAlpha float64 `mapstructure:"prior_alpha"`
+	PriorBeta  float64 `mapstructure:"prior_beta"`
+	// RankBonus — сколько «успехов» априори получает первая категория рекомендаций;
+	// у следующих бонус линейно убывает до нуля, так что любимые темы стартуют с форой.
+	RankBonus float64 `mapstructure:"rank_bonus"`
+	// StateTTL — сколько хранится состояние пользователя без новых наград.
+	StateTTL time.Duration `mapstructure:"state_ttl"`
+}
+
+func NewBanditConfig() (*BanditConfig, error) {
+	var cfg BanditConfig
+	if err := appconfig.ReadKey(RecommendationConfigPath, "bandit", &cfg); err != nil {
+		return nil, err
+	}
+
+	if cfg.PriorAlpha <= 0 || cfg.PriorBeta <= 0 {
+		return nil, fmt.Errorf("bandit: prior_alpha and prior_beta must be positive")
+	}
+	if cfg.RankBonus < 0 || cfg.StateTTL <= 0 {
+		return nil, fmt.Errorf("bandit: rank_bonus must not be negative and state_ttl must be positive")
+	}
+
+	return &cfg, nil
+}
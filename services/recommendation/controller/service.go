@@ -2,20 +2,47 @@ package controller
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"time"
 
 	recpb "github.com/NordCoder/Story/generated/api/proto/v1"
 	"github.com/NordCoder/Story/internal/entity"
 	"github.com/NordCoder/Story/internal/logger"
 	auth "github.com/NordCoder/Story/services/authorization/transport/http"
+	recentity "github.com/NordCoder/Story/services/recommendation/entity"
 	"github.com/NordCoder/Story/services/recommendation/usecase"
+	"github.com/NordCoder/Story/services/recommendation/worker"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// defaultPageSize — размер страницы ListMyCategories, если клиент его не указал.
+const defaultPageSize = 20
+
+var likeStateToProto = map[recentity.LikeState]recpb.LikeState{
+	recentity.LikeNone:    recpb.LikeState_LIKE_STATE_NONE,
+	recentity.LikeLiked:   recpb.LikeState_LIKE_STATE_LIKED,
+	recentity.LikeUnliked: recpb.LikeState_LIKE_STATE_UNLIKED,
+}
+
 type RecService interface {
 	LikeCategory(context.Context, *recpb.CategoryActionRequest) (*emptypb.Empty, error)
 	UnlikeCategory(context.Context, *recpb.CategoryActionRequest) (*emptypb.Empty, error)
-	GetUserRec(ctx context.Context) ([]entity.Category, error)
+	ReactToFact(context.Context, *recpb.ReactToFactRequest) (*emptypb.Empty, error)
+	RecordEvents(context.Context, *recpb.RecordEventsRequest) (*recpb.RecordEventsResponse, error)
+	ListInterestTopics(context.Context, *recpb.ListInterestTopicsRequest) (*recpb.ListInterestTopicsResponse, error)
+	SetInterests(context.Context, *recpb.SetInterestsRequest) (*emptypb.Empty, error)
+	GetTrending(context.Context, *recpb.GetTrendingRequest) (*recpb.GetTrendingResponse, error)
+	ListMyCategories(context.Context, *recpb.ListMyCategoriesRequest) (*recpb.ListMyCategoriesResponse, error)
+	ResetCategory(context.Context, *recpb.CategoryActionRequest) (*emptypb.Empty, error)
+	ClearPreferences(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
+	GetUserRec(ctx context.Context) ([]recentity.RecommendedCategory, error)
+	ChooseCategory(ctx context.Context, candidates []recentity.RecommendedCategory) recentity.RecommendedCategory
 }
 
 type RecServiceImpl struct {
@@ -28,7 +55,7 @@ func NewRecService(usecase usecase.RecUseCase) RecService {
 	}
 }
 
-func (s *RecServiceImpl) GetUserRec(ctx context.Context) ([]entity.Category, error) {
+func (s *RecServiceImpl) GetUserRec(ctx context.Context) ([]recentity.RecommendedCategory, error) {
 	id, err := auth.UserIDFromCtx(ctx)
 	if err != nil {
 		logger.LoggerFromContext(ctx).Info("Failed to get id from context", zap.Error(err))
@@ -37,6 +64,16 @@ func (s *RecServiceImpl) GetUserRec(ctx context.Context) ([]entity.Category, err
 	return s.usecase.GetUserRec(ctx, id)
 }
 
+// ChooseCategory выбирает категорию для показа среди рекомендованных; candidates не пуст.
+func (s *RecServiceImpl) ChooseCategory(ctx context.Context, candidates []recentity.RecommendedCategory) recentity.RecommendedCategory {
+	id, err := auth.UserIDFromCtx(ctx)
+	if err != nil {
+		logger.LoggerFromContext(ctx).Info("Failed to get id from context", zap.Error(err))
+		return candidates[0]
+	}
+	return s.usecase.ChooseCategory(ctx, id, candidates)
+}
+
 func (s *RecServiceImpl) LikeCategory(ctx context.Context, req *recpb.CategoryActionRequest) (*emptypb.Empty, error) {
 	if err := req.ValidateAll(); err != nil {
 		logger.LoggerFromContext(ctx).Info("LikeCategory validate fail", zap.Error(err))
@@ -62,3 +99,243 @@ func (s *RecServiceImpl) UnlikeCategory(ctx context.Context, req *recpb.Category
 	}
 	return &emptypb.Empty{}, s.usecase.UnlikeCategory(ctx, id, entity.Category(req.GetCategory()))
 }
+
+var reactionKinds = map[recpb.Reaction]recentity.ReactionKind{
+	recpb.Reaction_REACTION_LIKE:    recentity.ReactionLike,
+	recpb.Reaction_REACTION_DISLIKE: recentity.ReactionDislike,
+	recpb.Reaction_REACTION_SKIP:    recentity.ReactionSkip,
+}
+
+func (s *RecServiceImpl) ReactToFact(ctx context.Context, req *recpb.ReactToFactRequest) (*emptypb.Empty, error) {
+	if err := req.ValidateAll(); err != nil {
+		logger.LoggerFromContext(ctx).Info("ReactToFact validate fail", zap.Error(err))
+		return &emptypb.Empty{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+	id, err := auth.UserIDFromCtx(ctx)
+	if err != nil {
+		logger.LoggerFromContext(ctx).Info("Failed to get id from context", zap.Error(err))
+		return &emptypb.Empty{}, err
+	}
+
+	kind, ok := reactionKinds[req.GetReaction()]
+	if !ok {
+		return &emptypb.Empty{}, status.Error(codes.InvalidArgument, recentity.ErrUnknownReaction.Error())
+	}
+
+	dwell := time.Duration(req.GetDwellMs()) * time.Millisecond
+	err = s.usecase.ReactToFact(ctx, id, entity.FactID(req.GetFactId()), kind, dwell)
+	if errors.Is(err, entity.ErrFactNotFound) {
+		return &emptypb.Empty{}, status.Error(codes.NotFound, err.Error())
+	}
+	return &emptypb.Empty{}, err
+}
+
+var eventTypes = map[recpb.EventType]recentity.EventType{
+	recpb.EventType_EVENT_TYPE_IMPRESSION:     recentity.EventImpression,
+	recpb.EventType_EVENT_TYPE_OPEN_SOURCE:    recentity.EventOpenSource,
+	recpb.EventType_EVENT_TYPE_EXPAND_SUMMARY: recentity.EventExpandSummary,
+	recpb.EventType_EVENT_TYPE_SHARE:          recentity.EventShare,
+	recpb.EventType_EVENT_TYPE_TIME_ON_CARD:   recentity.EventTimeOnCard,
+}
+
+func (s *RecServiceImpl) RecordEvents(ctx context.Context, req *recpb.RecordEventsRequest) (*recpb.RecordEventsResponse, error) {
+	if err := req.ValidateAll(); err != nil {
+		logger.LoggerFromContext(ctx).Info("RecordEvents validate fail", zap.Error(err))
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	id, err := auth.UserIDFromCtx(ctx)
+	if err != nil {
+		logger.LoggerFromContext(ctx).Info("Failed to get id from context", zap.Error(err))
+		return nil, err
+	}
+
+	events := make([]recentity.Event, 0, len(req.GetEvents()))
+	for _, e := range req.GetEvents() {
+		eventType, ok := eventTypes[e.GetType()]
+		if !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown event type %v", e.GetType())
+		}
+		events = append(events, recentity.Event{
+			ID:         e.GetEventId(),
+			FactID:     entity.FactID(e.GetFactId()),
+			Type:       eventType,
+			Duration:   time.Duration(e.GetDurationMs()) * time.Millisecond,
+			OccurredAt: e.GetOccurredAt().AsTime(),
+		})
+	}
+
+	switch err := s.usecase.RecordEvents(ctx, id, events); {
+	case errors.Is(err, worker.ErrQueueFull):
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, usecase.ErrTooManyEvents):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, usecase.ErrEventsDisabled):
+		return nil, status.Error(codes.Unavailable, err.Error())
+	case err != nil:
+		return nil, err
+	}
+	return &recpb.RecordEventsResponse{Accepted: int32(len(events))}, nil
+}
+
+func (s *RecServiceImpl) ListInterestTopics(ctx context.Context, req *recpb.ListInterestTopicsRequest) (*recpb.ListInterestTopicsResponse, error) {
+	if err := req.ValidateAll(); err != nil {
+		logger.LoggerFromContext(ctx).Info("ListInterestTopics validate fail", zap.Error(err))
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	topics, err := s.usecase.ListInterestTopics(ctx, req.GetLang())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &recpb.ListInterestTopicsResponse{Topics: make([]*recpb.InterestTopic, 0, len(topics))}
+	for _, t := range topics {
+		resp.Topics = append(resp.Topics, topicToProto(t))
+	}
+	return resp, nil
+}
+
+func (s *RecServiceImpl) SetInterests(ctx context.Context, req *recpb.SetInterestsRequest) (*emptypb.Empty, error) {
+	if err := req.ValidateAll(); err != nil {
+		logger.LoggerFromContext(ctx).Info("SetInterests validate fail", zap.Error(err))
+		return &emptypb.Empty{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+	id, err := auth.UserIDFromCtx(ctx)
+	if err != nil {
+		logger.LoggerFromContext(ctx).Info("Failed to get id from context", zap.Error(err))
+		return &emptypb.Empty{}, err
+	}
+
+	categories := make([]entity.Category, len(req.GetCategories()))
+	for i, c := range req.GetCategories() {
+		categories[i] = entity.Category(c)
+	}
+	err = s.usecase.SetInterests(ctx, id, categories)
+	if errors.Is(err, usecase.ErrUnknownInterest) {
+		return &emptypb.Empty{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &emptypb.Empty{}, err
+}
+
+func topicToProto(t recentity.InterestTopic) *recpb.InterestTopic {
+	pb := &recpb.InterestTopic{
+		Category: string(t.Category),
+		Title:    t.Title,
+		ImageUrl: t.ImageURL,
+	}
+	for _, sub := range t.Subtopics {
+		pb.Subtopics = append(pb.Subtopics, topicToProto(sub))
+	}
+	return pb
+}
+
+func (s *RecServiceImpl) GetTrending(ctx context.Context, req *recpb.GetTrendingRequest) (*recpb.GetTrendingResponse, error) {
+	if err := req.ValidateAll(); err != nil {
+		logger.LoggerFromContext(ctx).Info("GetTrending validate fail", zap.Error(err))
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	window, trending, err := s.usecase.GetTrending(ctx, req.GetWindow(), int(req.GetLimit()))
+	switch {
+	case errors.Is(err, usecase.ErrUnknownWindow):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, usecase.ErrTrendingDisabled):
+		return nil, status.Error(codes.Unavailable, err.Error())
+	case err != nil:
+		return nil, err
+	}
+
+	resp := &recpb.GetTrendingResponse{Window: window, Categories: make([]*recpb.TrendingCategory, 0, len(trending))}
+	for _, t := range trending {
+		resp.Categories = append(resp.Categories, &recpb.TrendingCategory{Category: string(t.Category), Score: t.Score})
+	}
+	return resp, nil
+}
+
+func (s *RecServiceImpl) ListMyCategories(ctx context.Context, req *recpb.ListMyCategoriesRequest) (*recpb.ListMyCategoriesResponse, error) {
+	if err := req.ValidateAll(); err != nil {
+		logger.LoggerFromContext(ctx).Info("ListMyCategories validate fail", zap.Error(err))
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	offset, err := decodePageToken(req.GetPageToken())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+	}
+	pageSize := int(req.GetPageSize())
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+	id, err := auth.UserIDFromCtx(ctx)
+	if err != nil {
+		logger.LoggerFromContext(ctx).Info("Failed to get id from context", zap.Error(err))
+		return nil, err
+	}
+
+	prefs, total, err := s.usecase.ListMyCategories(ctx, id, pageSize, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &recpb.ListMyCategoriesResponse{
+		Categories: make([]*recpb.CategoryPreference, 0, len(prefs)),
+		TotalCount: int32(total),
+	}
+	for _, p := range prefs {
+		resp.Categories = append(resp.Categories, &recpb.CategoryPreference{
+			Category:  string(p.Category),
+			Score:     p.Score,
+			State:     likeStateToProto[p.State],
+			UpdatedAt: timestamppb.New(p.UpdatedAt),
+		})
+	}
+	if next := offset + len(prefs); len(prefs) > 0 && next < total {
+		resp.NextPageToken = encodePageToken(next)
+	}
+	return resp, nil
+}
+
+func (s *RecServiceImpl) ResetCategory(ctx context.Context, req *recpb.CategoryActionRequest) (*emptypb.Empty, error) {
+	if err := req.ValidateAll(); err != nil {
+		logger.LoggerFromContext(ctx).Info("ResetCategory validate fail", zap.Error(err))
+		return &emptypb.Empty{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+	id, err := auth.UserIDFromCtx(ctx)
+	if err != nil {
+		logger.LoggerFromContext(ctx).Info("Failed to get id from context", zap.Error(err))
+		return &emptypb.Empty{}, err
+	}
+
+	err = s.usecase.ResetCategory(ctx, id, entity.Category(req.GetCategory()))
+	if errors.Is(err, recentity.ErrPreferenceNotFound) {
+		return &emptypb.Empty{}, status.Error(codes.NotFound, err.Error())
+	}
+	return &emptypb.Empty{}, err
+}
+
+func (s *RecServiceImpl) ClearPreferences(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	id, err := auth.UserIDFromCtx(ctx)
+	if err != nil {
+		logger.LoggerFromContext(ctx).Info("Failed to get id from context", zap.Error(err))
+		return &emptypb.Empty{}, err
+	}
+	return &emptypb.Empty{}, s.usecase.ClearPreferences(ctx, id)
+}
+
+// encodePageToken прячет смещение в непрозрачный токен, чтобы клиенты не строили его сами.
+func encodePageToken(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func decodePageToken(token string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, err
+	}
+	offset, err := strconv.Atoi(string(raw))
+	if err != nil || offset < 0 {
+		return 0, errors.New("invalid page token")
+	}
+	return offset, nil
+}
@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/NordCoder/Story/internal/entity"
+	authentity "github.com/NordCoder/Story/services/authorization/entity"
+)
+
+// EventType — вид неявного сигнала от клиента.
+type EventType string
+
+const (
+	EventImpression    EventType = "impression"
+	EventOpenSource    EventType = "open_source"
+	EventExpandSummary EventType = "expand_summary"
+	EventShare         EventType = "share"
+	EventTimeOnCard    EventType = "time_on_card"
+)
+
+// Event — одно клиентское событие. ID генерирует клиент: повторная отправка того же
+// события (ретрай после ошибки) не создаёт дубликат.
+type Event struct {
+	ID         string
+	UserID     authentity.UserID
+	FactID     entity.FactID
+	Category   entity.Category // пустая, если факт уже истёк к моменту приёма
+	Type       EventType
+	Duration   time.Duration // для time_on_card
+	OccurredAt time.Time
+}
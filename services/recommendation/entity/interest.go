@@ -0,0 +1,11 @@
+package entity
+
+import "github.com/NordCoder/Story/internal/entity"
+
+// InterestTopic — тема, которую новый пользователь может выбрать при онбординге.
+type InterestTopic struct {
+	Category  entity.Category
+	Title     string // название на языке запроса
+	ImageURL  string
+	Subtopics []InterestTopic
+}
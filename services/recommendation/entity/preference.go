@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	"github.com/NordCoder/Story/internal/entity"
+)
+
+// ErrPreferenceNotFound возвращается, если у пользователя нет записи о категории.
+var ErrPreferenceNotFound = errors.New("category preference not found")
+
+// LikeState — явное отношение пользователя к категории.
+type LikeState string
+
+const (
+	// LikeNone — явной оценки нет, скор набран реакциями, сигналами или распространением.
+	LikeNone    LikeState = "none"
+	LikeLiked   LikeState = "liked"
+	LikeUnliked LikeState = "unliked"
+)
+
+// CategoryPreference — интерес пользователя к категории.
+type CategoryPreference struct {
+	Category entity.Category
+	// Score — скор с учётом затухания на момент запроса, не меньше нуля.
+	Score     float64
+	State     LikeState
+	UpdatedAt time.Time
+}
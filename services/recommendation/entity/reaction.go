@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	"github.com/NordCoder/Story/internal/entity"
+	authentity "github.com/NordCoder/Story/services/authorization/entity"
+)
+
+// ReactionKind — реакция пользователя на показанный факт.
+type ReactionKind string
+
+const (
+	ReactionLike    ReactionKind = "like"
+	ReactionDislike ReactionKind = "dislike"
+	ReactionSkip    ReactionKind = "skip"
+)
+
+// ErrUnknownReaction возвращается для реакции вне ReactionLike/Dislike/Skip.
+var ErrUnknownReaction = errors.New("unknown reaction")
+
+// ReactionEvent — сырое событие реакции, как оно пришло от клиента.
+type ReactionEvent struct {
+	UserID    authentity.UserID
+	FactID    entity.FactID
+	Category  entity.Category // категория факта на момент реакции
+	Kind      ReactionKind
+	Dwell     time.Duration // сколько факт был на экране
+	CreatedAt time.Time
+}
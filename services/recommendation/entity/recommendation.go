@@ -0,0 +1,36 @@
+package entity
+
+import "github.com/NordCoder/Story/internal/entity"
+
+// CategorySource — откуда взялась рекомендованная категория.
+type CategorySource string
+
+const (
+	// SourceLikes — скор категории у самого пользователя (лайки, реакции, распространение).
+	SourceLikes CategorySource = "likes"
+	// SourceSignals — неявные сигналы пользователя (RecordEvents).
+	SourceSignals CategorySource = "signals"
+	// SourceSimilar — категории, которые лайкают пользователи с похожими вкусами.
+	SourceSimilar CategorySource = "similar"
+	// SourcePopular — популярные категории по всем пользователям.
+	SourcePopular CategorySource = "popular"
+	// SourceOnboarding — стартовый набор для новых пользователей.
+	SourceOnboarding CategorySource = "onboarding"
+)
+
+// ScoredCategory — категория-кандидат со скором источника.
+type ScoredCategory struct {
+	Category entity.Category
+	Score    float64
+	// Via — лайкнутая категория, давшая наибольший вклад; заполняется только для похожих категорий.
+	Via entity.Category
+}
+
+// RecommendedCategory — категория в выдаче GetUserRec вместе с источником и скором в нём.
+// Скоры разных источников несравнимы между собой.
+type RecommendedCategory struct {
+	Category entity.Category
+	Source   CategorySource
+	Score    float64
+	Via      entity.Category
+}
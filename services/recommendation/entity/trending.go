@@ -0,0 +1,9 @@
+package entity
+
+import "github.com/NordCoder/Story/internal/entity"
+
+// TrendingCategory — категория и её скор вовлечённости за окно.
+type TrendingCategory struct {
+	Category entity.Category
+	Score    float64
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	entity2 "github.com/NordCoder/Story/internal/entity"
+	"github.com/NordCoder/Story/services/authorization/entity"
+	"github.com/go-redis/redis/v8"
+)
+
+// BanditStats — накопленные награды категории: Successes — сумма наград, Failures — сумма (1 − награда).
+type BanditStats struct {
+	Successes float64
+	Failures  float64
+}
+
+// BanditStore хранит состояние бандита пользователя в Redis: один hash на пользователя,
+// по два поля на категорию. Hash живёт ttl с последнего обновления.
+type BanditStore interface {
+	// Stats возвращает статистику по categories; категорий без истории в ответе нет.
+	Stats(ctx context.Context, userID entity.UserID, categories []entity2.Category) (map[entity2.Category]BanditStats, error)
+	// Reward учитывает награду из [0, 1] за показ категории.
+	Reward(ctx context.Context, userID entity.UserID, category entity2.Category, reward float64) error
+	// Forget удаляет статистику категории.
+	Forget(ctx context.Context, userID entity.UserID, category entity2.Category) error
+	// Clear удаляет всё состояние пользователя.
+	Clear(ctx context.Context, userID entity.UserID) error
+}
+
+type banditStoreImpl struct {
+	client    redis.UniversalClient
+	ttl       time.Duration
+	keyPrefix string
+}
+
+// NewBanditStore создаёт стор бандита. Каждая команда трогает один hash пользователя,
+// поэтому hash tag не нужен: в кластере пользователи расходятся по всем слотам.
+func NewBanditStore(client redis.UniversalClient, ttl time.Duration) BanditStore {
+	return &banditStoreImpl{client: client, ttl: ttl, keyPrefix: "bandit:"}
+}
+
+func (s *banditStoreImpl) key(userID entity.UserID) string {
+	return s.keyPrefix + string(userID)
+}
+
+func successField(c entity2.Category) string { return string(c) + ":s" }
+func failureField(c entity2.Category) string { return string(c) + ":f" }
+
+func (s *banditStoreImpl) Stats(ctx context.Context, userID entity.UserID, categories []entity2.Category) (map[entity2.Category]BanditStats, error) {
+	if len(categories) == 0 {
+		return nil, nil
+	}
+	fields := make([]string, 0, 2*len(categories))
+	for _, c := range categories {
+		fields = append(fields, successField(c), failureField(c))
+	}
+	values, err := s.client.HMGet(ctx, s.key(userID), fields...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[entity2.Category]BanditStats, len(categories))
+	for i, c := range categories {
+		succ, okS := parseFloatField(values[2*i])
+		fail, okF := parseFloatField(values[2*i+1])
+		if okS || okF {
+			stats[c] = BanditStats{Successes: succ, Failures: fail}
+		}
+	}
+	return stats, nil
+}
+
+func (s *banditStoreImpl) Reward(ctx context.Context, userID entity.UserID, category entity2.Category, reward float64) error {
+	key := s.key(userID)
+	_, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HIncrByFloat(ctx, key, successField(category), reward)
+		pipe.HIncrByFloat(ctx, key, failureField(category), 1-reward)
+		pipe.Expire(ctx, key, s.ttl)
+		return nil
+	})
+	return err
+}
+
+func (s *banditStoreImpl) Forget(ctx context.Context, userID entity.UserID, category entity2.Category) error {
+	return s.client.HDel(ctx, s.key(userID), successField(category), failureField(category)).Err()
+}
+
+func (s *banditStoreImpl) Clear(ctx context.Context, userID entity.UserID) error {
+	return s.client.Del(ctx, s.key(userID)).Err()
+}
+
+// parseFloatField разбирает значение из HMGET; отсутствующее поле приходит как nil.
+func parseFloatField(v interface{}) (float64, bool) {
+	str, ok := v.(string)
+	if !ok {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return 0, false
+	}
+	return f, true
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	entity2 "github.com/NordCoder/Story/internal/entity"
+	"github.com/NordCoder/Story/services/authorization/entity"
+)
+
+// memoryBanditStore хранит состояние бандита в памяти процесса — для запуска без Redis.
+// Как и в Redis, состояние пользователя живёт ttl с последней награды; истёкшее удаляется при чтении.
+type memoryBanditStore struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	now   func() time.Time
+	users map[entity.UserID]*memoryBanditState
+}
+
+type memoryBanditState struct {
+	stats     map[entity2.Category]BanditStats
+	expiresAt time.Time
+}
+
+func NewMemoryBanditStore(ttl time.Duration) BanditStore {
+	return &memoryBanditStore{ttl: ttl, now: time.Now, users: make(map[entity.UserID]*memoryBanditState)}
+}
+
+func (s *memoryBanditStore) Stats(_ context.Context, userID entity.UserID, categories []entity2.Category) (map[entity2.Category]BanditStats, error) {
+	if len(categories) == 0 {
+		return nil, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := make(map[entity2.Category]BanditStats, len(categories))
+	state := s.state(userID)
+	if state == nil {
+		return stats, nil
+	}
+	for _, c := range categories {
+		if st, ok := state.stats[c]; ok {
+			stats[c] = st
+		}
+	}
+	return stats, nil
+}
+
+func (s *memoryBanditStore) Reward(_ context.Context, userID entity.UserID, category entity2.Category, reward float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.state(userID)
+	if state == nil {
+		state = &memoryBanditState{stats: make(map[entity2.Category]BanditStats)}
+		s.users[userID] = state
+	}
+	st := state.stats[category]
+	st.Successes += reward
+	st.Failures += 1 - reward
+	state.stats[category] = st
+	state.expiresAt = s.now().Add(s.ttl)
+	return nil
+}
+
+func (s *memoryBanditStore) Forget(_ context.Context, userID entity.UserID, category entity2.Category) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if state := s.state(userID); state != nil {
+		delete(state.stats, category)
+	}
+	return nil
+}
+
+func (s *memoryBanditStore) Clear(_ context.Context, userID entity.UserID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.users, userID)
+	return nil
+}
+
+// state вызывается под mu; истёкшее состояние удаляется.
+func (s *memoryBanditStore) state(userID entity.UserID) *memoryBanditState {
+	state, ok := s.users[userID]
+	if !ok {
+		return nil
+	}
+	if !s.now().Before(state.expiresAt) {
+		delete(s.users, userID)
+		return nil
+	}
+	return state
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	entity2 "github.com/NordCoder/Story/internal/entity"
+	"github.com/NordCoder/Story/services/authorization/entity"
+	recentity "github.com/NordCoder/Story/services/recommendation/entity"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// EventRepository хранит неявные сигналы и отдаёт их агрегаты по категориям.
+type EventRepository interface {
+	// SaveBatch пишет события одним запросом; события с уже сохранённым ID пропускаются.
+	SaveBatch(ctx context.Context, events []recentity.Event) error
+	// TopSignalCategories возвращает до limit категорий с наибольшим весом сигналов пользователя с момента since.
+	TopSignalCategories(ctx context.Context, userID entity.UserID, since time.Time, limit int) ([]recentity.ScoredCategory, error)
+}
+
+type eventRepositoryImpl struct {
+	db *pgxpool.Pool
+}
+
+func NewEventRepository(pool *pgxpool.Pool) EventRepository {
+	return &eventRepositoryImpl{db: pool}
+}
+
+func (r eventRepositoryImpl) SaveBatch(ctx context.Context, events []recentity.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	var (
+		ids        = make([]string, len(events))
+		users      = make([]string, len(events))
+		facts      = make([]string, len(events))
+		categories = make([]string, len(events))
+		types      = make([]string, len(events))
+		durations  = make([]int64, len(events))
+		occurred   = make([]time.Time, len(events))
+	)
+	for i, e := range events {
+		ids[i] = e.ID
+		users[i] = string(e.UserID)
+		facts[i] = string(e.FactID)
+		categories[i] = string(e.Category)
+		types[i] = string(e.Type)
+		durations[i] = e.Duration.Milliseconds()
+		occurred[i] = e.OccurredAt
+	}
+
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO user_events (event_id, user_id, fact_id, category, event_type, duration_ms, occurred_at)
+			SELECT e::uuid, u::uuid, f::uuid, c, t, d, o
+			FROM UNNEST($1::text[], $2::text[], $3::text[], $4::text[], $5::text[], $6::bigint[], $7::timestamptz[])
+				AS x(e, u, f, c, t, d, o)
+			ON CONFLICT (event_id) DO NOTHING`,
+		ids, users, facts, categories, types, durations, occurred)
+	return err
+}
+
+// TopSignalCategories взвешивает события: переход к источнику 2, раскрытие 1, шаринг 3,
+// время на карточке — 1 за каждые 10 секунд, но не больше 3. Показы сами по себе веса не несут.
+func (r eventRepositoryImpl) TopSignalCategories(ctx context.Context, userID entity.UserID, since time.Time, limit int) ([]recentity.ScoredCategory, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT category, score::float8 FROM (
+			SELECT category, SUM(CASE event_type
+				WHEN 'open_source'    THEN 2
+				WHEN 'expand_summary' THEN 1
+				WHEN 'share'          THEN 3
+				WHEN 'time_on_card'   THEN LEAST(duration_ms / 10000.0, 3)
+				ELSE 0 END) AS score
+			FROM user_events
+			WHERE user_id = $1 AND occurred_at >= $2 AND category <> ''
+			GROUP BY category
+		) s
+		WHERE score > 0
+		ORDER BY score DESC
+		LIMIT $3`,
+		userID, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var scored []recentity.ScoredCategory
+	for rows.Next() {
+		var (
+			c     string
+			score float64
+		)
+		if err := rows.Scan(&c, &score); err != nil {
+			return nil, err
+		}
+		scored = append(scored, recentity.ScoredCategory{Category: entity2.Category(c), Score: score})
+	}
+	return scored, rows.Err()
+}
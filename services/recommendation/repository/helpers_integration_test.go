@@ -0,0 +1,43 @@
+//go:build integration_test
+
+package repository
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/NordCoder/Story/internal/infrastructure/pgtest"
+	"github.com/NordCoder/Story/services/authorization/entity"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Интеграционные тесты идут против настоящего Postgres (make integration-test, см. pgtest).
+// Каждый тест работает со своими пользователями и категориями, поэтому запросы по всем
+// пользователям (тренды, похожие категории) проверяются только на категориях теста.
+
+// insertLike пишет строку интереса напрямую: updated_at задаётся явно, чтобы проверять затухание
+// без ожидания (триггер сдвигает его только при UPDATE).
+func insertLike(t *testing.T, pool *pgxpool.Pool, userID entity.UserID, category string, score float64, updatedAt time.Time, state string) {
+	t.Helper()
+	pgtest.Exec(t, pool,
+		`INSERT INTO user_category_likes (user_id, category, score, state, updated_at) VALUES ($1, $2, $3, $4, $5)`,
+		string(userID), category, score, state, updatedAt)
+}
+
+// storedScore возвращает скор строки как он лежит в таблице, без затухания.
+func storedScore(t *testing.T, pool *pgxpool.Pool, userID entity.UserID, category string) float64 {
+	t.Helper()
+	var score float64
+	if err := pool.QueryRow(context.Background(),
+		`SELECT score FROM user_category_likes WHERE user_id = $1 AND category = $2`,
+		string(userID), category).Scan(&score); err != nil {
+		t.Fatal(err)
+	}
+	return score
+}
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-3
+}
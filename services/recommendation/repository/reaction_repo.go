@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/NordCoder/Story/services/recommendation/entity"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ReactionRepository хранит сырые реакции пользователей на факты.
+type ReactionRepository interface {
+	Save(ctx context.Context, event entity.ReactionEvent) error
+}
+
+type reactionRepositoryImpl struct {
+	db *pgxpool.Pool
+}
+
+func NewReactionRepository(pool *pgxpool.Pool) ReactionRepository {
+	return &reactionRepositoryImpl{db: pool}
+}
+
+func (r reactionRepositoryImpl) Save(ctx context.Context, event entity.ReactionEvent) error {
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO fact_reactions (user_id, fact_id, category, reaction, dwell_ms, created_at)
+		 VALUES ($1, $2, $3, $4, $5, $6)`,
+		event.UserID, string(event.FactID), string(event.Category), string(event.Kind),
+		event.Dwell.Milliseconds(), event.CreatedAt)
+	return err
+}
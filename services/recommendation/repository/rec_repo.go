@@ -3,60 +3,211 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	entity2 "github.com/NordCoder/Story/internal/entity"
 
 	"github.com/NordCoder/Story/services/authorization/entity"
+	recentity "github.com/NordCoder/Story/services/recommendation/entity"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-var ErrNotEnoughData = errors.New("not enough liked categories from user")
+// ErrNotEnoughData возвращается, когда для пользователя не нашлось ни одной категории.
+var ErrNotEnoughData = errors.New("not enough data to recommend categories")
 
+// RecRepository хранит интересы пользователя по категориям. Скор никогда не опускается ниже нуля.
 type RecRepository interface {
-	Adjust(ctx context.Context, userID entity.UserID, category entity2.Category, delta int) error
-	BulkAdjust(ctx context.Context, userID entity.UserID, categories []entity2.Category, delta int) error
-	TopCategories(ctx context.Context, userID entity.UserID) ([]entity2.Category, error)
+	Adjust(ctx context.Context, userID entity.UserID, category entity2.Category, delta float64) error
+	BulkAdjust(ctx context.Context, userID entity.UserID, categories []entity2.Category, delta float64) error
+	TopCategories(ctx context.Context, userID entity.UserID, limit int) ([]recentity.ScoredCategory, error)
+	PopularCategories(ctx context.Context, limit int) ([]recentity.ScoredCategory, error)
+	// SetLikeState переводит категорию в состояние state и сдвигает скор на delta. Если категория
+	// уже в этом состоянии, ничего не меняется и возвращается false.
+	SetLikeState(ctx context.Context, userID entity.UserID, category entity2.Category, state recentity.LikeState, delta float64) (bool, error)
+	// Unliked возвращает категории, которые пользователь явно дизлайкнул.
+	Unliked(ctx context.Context, userID entity.UserID) ([]entity2.Category, error)
+	// ListPreferences возвращает страницу категорий пользователя с положительным скором или явной оценкой
+	// по убыванию скора и общее число таких категорий.
+	ListPreferences(ctx context.Context, userID entity.UserID, limit, offset int) ([]recentity.CategoryPreference, int, error)
+	// Reset забывает всё о категории пользователя; ErrPreferenceNotFound, если записи не было.
+	Reset(ctx context.Context, userID entity.UserID, category entity2.Category) error
+	// Clear забывает все категории пользователя.
+	Clear(ctx context.Context, userID entity.UserID) error
+	// Seed поднимает скор категорий как минимум до weight; повторный вызов ничего не удваивает.
+	Seed(ctx context.Context, userID entity.UserID, categories []entity2.Category, weight float64) error
 }
 
 type recRepositoryImpl struct {
 	db *pgxpool.Pool
+	// halfLife — за сколько скор категории убывает вдвое; 0 — без затухания.
+	halfLife time.Duration
 }
 
-func NewRecRepository(pool *pgxpool.Pool) RecRepository {
-	return &recRepositoryImpl{db: pool}
+type RecOption func(*recRepositoryImpl)
+
+// WithHalfLife включает экспоненциальное затухание скоров с заданным периодом полураспада.
+func WithHalfLife(d time.Duration) RecOption {
+	return func(r *recRepositoryImpl) { r.halfLife = d }
+}
+
+func NewRecRepository(pool *pgxpool.Pool, opts ...RecOption) RecRepository {
+	repo := &recRepositoryImpl{db: pool}
+	for _, o := range opts {
+		o(repo)
+	}
+	return repo
 }
 
-// Adjust increments or decrements the like count by delta (delta may be negative).
-func (r recRepositoryImpl) Adjust(ctx context.Context, userID entity.UserID, category entity2.Category, delta int) error {
+// decayedScore возвращает SQL-выражение скора строки alias, приведённого к текущему моменту.
+// Затухание считается лениво от updated_at: хранимый скор актуален на момент последнего изменения,
+// а триггер обновляет updated_at при каждом UPDATE.
+func (r recRepositoryImpl) decayedScore(alias string) string {
+	if r.halfLife <= 0 {
+		return alias + ".score"
+	}
+	return fmt.Sprintf("%[1]s.score * power(0.5, extract(epoch FROM NOW() - %[1]s.updated_at) / %[2]f)", alias, r.halfLife.Seconds())
+}
+
+// Adjust increments or decrements the category score by delta (delta may be negative or fractional).
+// The stored score is decayed to now before delta is added, and the result is clamped at zero.
+func (r recRepositoryImpl) Adjust(ctx context.Context, userID entity.UserID, category entity2.Category, delta float64) error {
 	_, err := r.db.Exec(ctx,
-		`INSERT INTO user_category_likes(user_id, category, cnt) VALUES($1, $2, $3)
-		 ON CONFLICT (user_id, category) DO UPDATE SET cnt = user_category_likes.cnt + $3`,
+		`INSERT INTO user_category_likes(user_id, category, score) VALUES($1, $2, GREATEST($3::float8, 0))
+		 ON CONFLICT (user_id, category) DO UPDATE SET score = GREATEST(`+r.decayedScore("user_category_likes")+` + $3, 0)`,
 		userID, string(category), delta)
 	return err
 }
 
-func (r recRepositoryImpl) BulkAdjust(ctx context.Context, userID entity.UserID, categories []entity2.Category, delta int) error {
+func (r recRepositoryImpl) SetLikeState(ctx context.Context, userID entity.UserID, category entity2.Category, state recentity.LikeState, delta float64) (bool, error) {
+	// WHERE в DO UPDATE делает повтор no-op: строка не трогается, и updated_at не сдвигается
+	tag, err := r.db.Exec(ctx,
+		`INSERT INTO user_category_likes(user_id, category, score, state) VALUES($1, $2, GREATEST($3::float8, 0), $4)
+		 ON CONFLICT (user_id, category) DO UPDATE
+			SET score = GREATEST(`+r.decayedScore("user_category_likes")+` + $3, 0), state = $4
+			WHERE user_category_likes.state <> $4`,
+		userID, string(category), delta, string(state))
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r recRepositoryImpl) Unliked(ctx context.Context, userID entity.UserID) ([]entity2.Category, error) {
+	return r.queryCategories(ctx,
+		`SELECT category FROM user_category_likes WHERE user_id = $1 AND state = 'unliked'`,
+		userID)
+}
+
+func (r recRepositoryImpl) ListPreferences(ctx context.Context, userID entity.UserID, limit, offset int) ([]recentity.CategoryPreference, int, error) {
+	var total int
+	if err := r.db.QueryRow(ctx,
+		`SELECT COUNT(*) FROM user_category_likes WHERE user_id = $1 AND (score > 0 OR state <> 'none')`,
+		userID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.Query(ctx,
+		`SELECT category, `+r.decayedScore("l")+` AS current, state, updated_at
+		 FROM user_category_likes AS l
+		 WHERE user_id = $1 AND (score > 0 OR state <> 'none')
+		 ORDER BY current DESC, category
+		 LIMIT $2 OFFSET $3`,
+		userID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var prefs []recentity.CategoryPreference
+	for rows.Next() {
+		var (
+			p        recentity.CategoryPreference
+			category string
+			state    string
+		)
+		if err := rows.Scan(&category, &p.Score, &state, &p.UpdatedAt); err != nil {
+			return nil, 0, err
+		}
+		p.Category = entity2.Category(category)
+		p.State = recentity.LikeState(state)
+		prefs = append(prefs, p)
+	}
+	return prefs, total, rows.Err()
+}
+
+func (r recRepositoryImpl) Reset(ctx context.Context, userID entity.UserID, category entity2.Category) error {
+	tag, err := r.db.Exec(ctx,
+		`DELETE FROM user_category_likes WHERE user_id = $1 AND category = $2`,
+		userID, string(category))
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return recentity.ErrPreferenceNotFound
+	}
+	return nil
+}
+
+func (r recRepositoryImpl) Clear(ctx context.Context, userID entity.UserID) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM user_category_likes WHERE user_id = $1`, userID)
+	return err
+}
+
+func (r recRepositoryImpl) BulkAdjust(ctx context.Context, userID entity.UserID, categories []entity2.Category, delta float64) error {
 	cats := make([]string, len(categories))
 	for i, c := range categories {
 		cats[i] = string(c)
 	}
 	_, err := r.db.Exec(ctx,
-		`INSERT INTO user_category_likes (user_id, category, cnt)
-			SELECT $1, c, $3
+		`INSERT INTO user_category_likes (user_id, category, score)
+			SELECT $1, c, GREATEST($3::float8, 0)
 			FROM UNNEST($2::text[]) AS t(c)
 			ON CONFLICT (user_id, category)
-			DO UPDATE SET cnt = user_category_likes.cnt + $3`,
+			DO UPDATE SET score = GREATEST(`+r.decayedScore("user_category_likes")+` + $3, 0)`,
 		userID, cats, delta)
 	return err
 }
 
-func (r recRepositoryImpl) TopCategories(ctx context.Context, userID entity.UserID) ([]entity2.Category, error) {
-	rows, err := r.db.Query(ctx,
-		`SELECT category FROM user_category_likes
-		 WHERE user_id = $1
-		 ORDER BY cnt DESC
-		 LIMIT 10`,
-		userID)
+func (r recRepositoryImpl) Seed(ctx context.Context, userID entity.UserID, categories []entity2.Category, weight float64) error {
+	cats := make([]string, len(categories))
+	for i, c := range categories {
+		cats[i] = string(c)
+	}
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO user_category_likes (user_id, category, score)
+			SELECT $1, c, $3
+			FROM UNNEST($2::text[]) AS t(c)
+			ON CONFLICT (user_id, category)
+			DO UPDATE SET score = GREATEST(`+r.decayedScore("user_category_likes")+`, $3)`,
+		userID, cats, weight)
+	return err
+}
+
+// TopCategories возвращает до limit категорий пользователя с наибольшим положительным скором.
+// Список может быть короче limit или пустым — добором занимается cold start в usecase.
+func (r recRepositoryImpl) TopCategories(ctx context.Context, userID entity.UserID, limit int) ([]recentity.ScoredCategory, error) {
+	return r.queryScored(ctx,
+		`SELECT category, `+r.decayedScore("l")+` FROM user_category_likes AS l
+		 WHERE user_id = $1 AND score > 0 AND state <> 'unliked'
+		 ORDER BY `+r.decayedScore("l")+` DESC
+		 LIMIT $2`,
+		userID, limit)
+}
+
+// PopularCategories возвращает до limit категорий с наибольшим суммарным скором по всем пользователям.
+func (r recRepositoryImpl) PopularCategories(ctx context.Context, limit int) ([]recentity.ScoredCategory, error) {
+	return r.queryScored(ctx,
+		`SELECT category, SUM(`+r.decayedScore("l")+`) FROM user_category_likes AS l
+		 WHERE score > 0
+		 GROUP BY category
+		 ORDER BY SUM(`+r.decayedScore("l")+`) DESC
+		 LIMIT $1`,
+		limit)
+}
+
+func (r recRepositoryImpl) queryCategories(ctx context.Context, query string, args ...interface{}) ([]entity2.Category, error) {
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -69,10 +220,26 @@ func (r recRepositoryImpl) TopCategories(ctx context.Context, userID entity.User
 		}
 		categories = append(categories, entity2.Category(c))
 	}
+	return categories, rows.Err()
+}
 
-	if len(categories) < 10 {
-		return nil, ErrNotEnoughData
+// queryScored выполняет запрос, возвращающий пары (category, score).
+func (r recRepositoryImpl) queryScored(ctx context.Context, query string, args ...interface{}) ([]recentity.ScoredCategory, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
 	}
-
-	return categories, rows.Err()
+	defer rows.Close()
+	var scored []recentity.ScoredCategory
+	for rows.Next() {
+		var (
+			c     string
+			score float64
+		)
+		if err := rows.Scan(&c, &score); err != nil {
+			return nil, err
+		}
+		scored = append(scored, recentity.ScoredCategory{Category: entity2.Category(c), Score: score})
+	}
+	return scored, rows.Err()
 }
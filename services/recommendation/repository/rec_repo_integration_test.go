@@ -0,0 +1,116 @@
+//go:build integration_test
+
+package repository
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	entity2 "github.com/NordCoder/Story/internal/entity"
+	"github.com/NordCoder/Story/internal/infrastructure/pgtest"
+)
+
+func TestTopCategoriesDecaysByHalfLife(t *testing.T) {
+	pool := pgtest.NewPool(t)
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		halfLife time.Duration
+		age      time.Duration
+		want     float64
+	}{
+		{"fresh", time.Hour, 0, 8},
+		{"one half-life", time.Hour, time.Hour, 4},
+		{"two half-lives", time.Hour, 2 * time.Hour, 2},
+		{"half of a half-life", time.Hour, 30 * time.Minute, 8 / math.Sqrt2},
+		{"decay disabled", 0, 2 * time.Hour, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := pgtest.NewUser(t, pool)
+			insertLike(t, pool, user, "История", 8, now.Add(-tt.age), "none")
+
+			repo := NewRecRepository(pool, WithHalfLife(tt.halfLife))
+			top, err := repo.TopCategories(context.Background(), user, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(top) != 1 || !approxEqual(top[0].Score, tt.want) {
+				t.Errorf("TopCategories() = %+v, want score %.3f", top, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdjustDecaysThenClampsAtZero(t *testing.T) {
+	pool := pgtest.NewPool(t)
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		stored float64
+		age    time.Duration
+		delta  float64
+		want   float64
+	}{
+		{"adds to the decayed score", 4, time.Hour, 1, 3},
+		{"subtracts from the decayed score", 4, time.Hour, -1, 1},
+		{"clamps at zero", 1, time.Hour, -2, 0},
+		{"delta larger than the decayed score clamps at zero", 1, 10 * time.Hour, -0.01, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := pgtest.NewUser(t, pool)
+			insertLike(t, pool, user, "История", tt.stored, now.Add(-tt.age), "none")
+
+			repo := NewRecRepository(pool, WithHalfLife(time.Hour))
+			if err := repo.Adjust(context.Background(), user, "История", tt.delta); err != nil {
+				t.Fatal(err)
+			}
+			if got := storedScore(t, pool, user, "История"); !approxEqual(got, tt.want) {
+				t.Errorf("score after Adjust(%v) = %.4f, want %.4f", tt.delta, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("negative delta on a new category stores zero", func(t *testing.T) {
+		user := pgtest.NewUser(t, pool)
+		repo := NewRecRepository(pool, WithHalfLife(time.Hour))
+		if err := repo.Adjust(context.Background(), user, entity2.Category("Физика"), -1); err != nil {
+			t.Fatal(err)
+		}
+		if got := storedScore(t, pool, user, "Физика"); got != 0 {
+			t.Errorf("score = %v, want 0", got)
+		}
+	})
+}
+
+func TestBulkAdjustAppliesFractionalWeights(t *testing.T) {
+	pool := pgtest.NewPool(t)
+	user := pgtest.NewUser(t, pool)
+	insertLike(t, pool, user, "Физика", 1, time.Now(), "none")
+	repo := NewRecRepository(pool)
+	categories := []entity2.Category{"Физика", "Химия"}
+
+	steps := []struct {
+		delta float64
+		want  map[string]float64
+	}{
+		// распространение добавляет decay_factor^depth, то есть дробные веса
+		{0.25, map[string]float64{"Физика": 1.25, "Химия": 0.25}},
+		{-0.5, map[string]float64{"Физика": 0.75, "Химия": 0}},
+	}
+	for _, step := range steps {
+		if err := repo.BulkAdjust(context.Background(), user, categories, step.delta); err != nil {
+			t.Fatal(err)
+		}
+		for category, want := range step.want {
+			if got := storedScore(t, pool, user, category); !approxEqual(got, want) {
+				t.Errorf("after BulkAdjust(%v): %s = %.4f, want %.4f", step.delta, category, got, want)
+			}
+		}
+	}
+}
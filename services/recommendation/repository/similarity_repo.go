@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	entity2 "github.com/NordCoder/Story/internal/entity"
+	"github.com/NordCoder/Story/services/authorization/entity"
+	"github.com/NordCoder/Story/services/recommendation/config"
+	recentity "github.com/NordCoder/Story/services/recommendation/entity"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrRebuildInProgress возвращается Rebuild, если модель прямо сейчас пересобирает другой инстанс.
+var ErrRebuildInProgress = errors.New("category similarity rebuild is already in progress")
+
+// SimilarityRepository строит и читает item-item модель похожих категорий.
+type SimilarityRepository interface {
+	// Rebuild пересчитывает category_similarity целиком и возвращает число сохранённых пар.
+	Rebuild(ctx context.Context, cfg *config.CollaborativeConfig) (int64, error)
+	// SimilarCategories возвращает до limit категорий, похожих на лайкнутые пользователем.
+	// Категории, которые у пользователя уже есть с положительным скором или явно дизлайкнуты, пропускаются.
+	SimilarCategories(ctx context.Context, userID entity.UserID, limit int) ([]recentity.ScoredCategory, error)
+}
+
+// similarityRepositoryImpl разделяет с recRepositoryImpl расчёт затухающего скора.
+type similarityRepositoryImpl struct {
+	recRepositoryImpl
+}
+
+// NewSimilarityRepository принимает те же опции, что и NewRecRepository: период полураспада
+// должен совпадать, чтобы модель видела те же скоры, что и рекомендации.
+func NewSimilarityRepository(pool *pgxpool.Pool, opts ...RecOption) SimilarityRepository {
+	repo := &similarityRepositoryImpl{recRepositoryImpl{db: pool}}
+	for _, o := range opts {
+		o(&repo.recRepositoryImpl)
+	}
+	return repo
+}
+
+// Rebuild считает косинусную близость категорий по векторам скоров пользователей.
+// У каждого пользователя берутся только max_user_categories лучших категорий: самоджойн
+// квадратичен по числу категорий пользователя. Пара сохраняется, если её лайкнули вместе
+// хотя бы min_co_likes пользователей; на категорию — не больше neighbors соседей.
+// Старая модель подменяется в одной транзакции, читатели не видят пустую таблицу;
+// advisory-лок не даёт двум инстансам пересобирать её одновременно.
+func (r similarityRepositoryImpl) Rebuild(ctx context.Context, cfg *config.CollaborativeConfig) (int64, error) {
+	var saved int64
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		var locked bool
+		if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock(hashtext('category_similarity'))`).Scan(&locked); err != nil {
+			return err
+		}
+		if !locked {
+			return ErrRebuildInProgress
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM category_similarity`); err != nil {
+			return err
+		}
+		tag, err := tx.Exec(ctx,
+			`WITH likes AS (
+				SELECT user_id, category, score FROM (
+					SELECT user_id, category, `+r.decayedScore("l")+` AS score,
+						row_number() OVER (PARTITION BY user_id ORDER BY `+r.decayedScore("l")+` DESC) AS rn
+					FROM user_category_likes AS l
+					WHERE score > 0 AND state <> 'unliked'
+				) t
+				WHERE rn <= $1
+			),
+			norms AS (
+				SELECT category, sqrt(SUM(score * score)) AS norm FROM likes GROUP BY category
+			),
+			pairs AS (
+				SELECT a.category AS category, b.category AS similar,
+					SUM(a.score * b.score) AS dot, COUNT(*) AS co_likes
+				FROM likes a
+				JOIN likes b ON a.user_id = b.user_id AND a.category <> b.category
+				GROUP BY a.category, b.category
+				HAVING COUNT(*) >= $2
+			),
+			ranked AS (
+				SELECT p.category, p.similar, p.dot / (na.norm * nb.norm) AS score, p.co_likes,
+					row_number() OVER (PARTITION BY p.category ORDER BY p.dot / (na.norm * nb.norm) DESC) AS rn
+				FROM pairs p
+				JOIN norms na ON na.category = p.category
+				JOIN norms nb ON nb.category = p.similar
+			)
+			INSERT INTO category_similarity (category, similar, score, co_likes)
+			SELECT category, similar, score, co_likes FROM ranked WHERE rn <= $3`,
+			cfg.MaxUserCategories, cfg.MinCoLikes, cfg.Neighbors)
+		if err != nil {
+			return err
+		}
+		saved = tag.RowsAffected()
+		return nil
+	})
+	return saved, err
+}
+
+// SimilarCategories ранжирует соседей по сумме близостей, взвешенных скором исходной категории;
+// Via — лайкнутая категория с наибольшим вкладом.
+func (r similarityRepositoryImpl) SimilarCategories(ctx context.Context, userID entity.UserID, limit int) ([]recentity.ScoredCategory, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT s.similar,
+			SUM(`+r.decayedScore("l")+` * s.score) AS score,
+			(array_agg(l.category ORDER BY `+r.decayedScore("l")+` * s.score DESC))[1] AS via
+		 FROM user_category_likes AS l
+		 JOIN category_similarity AS s ON s.category = l.category
+		 WHERE l.user_id = $1 AND l.score > 0 AND l.state <> 'unliked'
+		   AND NOT EXISTS (
+			SELECT 1 FROM user_category_likes AS own
+			WHERE own.user_id = $1 AND own.category = s.similar AND (own.state = 'unliked' OR own.score > 0)
+		   )
+		 GROUP BY s.similar
+		 ORDER BY score DESC
+		 LIMIT $2`,
+		userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var scored []recentity.ScoredCategory
+	for rows.Next() {
+		var (
+			c, via string
+			score  float64
+		)
+		if err := rows.Scan(&c, &score, &via); err != nil {
+			return nil, err
+		}
+		scored = append(scored, recentity.ScoredCategory{Category: entity2.Category(c), Score: score, Via: entity2.Category(via)})
+	}
+	return scored, rows.Err()
+}
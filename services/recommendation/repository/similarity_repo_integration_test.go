@@ -0,0 +1,107 @@
+//go:build integration_test
+
+package repository
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/NordCoder/Story/internal/infrastructure/pgtest"
+	"github.com/NordCoder/Story/services/recommendation/config"
+)
+
+func TestRebuildComputesCosineSimilarity(t *testing.T) {
+	pool := pgtest.NewPool(t)
+	x, y, z := pgtest.Name("x"), pgtest.Name("y"), pgtest.Name("z")
+	t.Cleanup(func() {
+		pgtest.Exec(t, pool, `DELETE FROM category_similarity WHERE category = ANY($1) OR similar = ANY($1)`, []string{x, y, z})
+	})
+
+	// векторы категорий по пользователям: x = (1, 1, 0), y = (1, 1, 2), z = (0, 1, 2)
+	now := time.Now()
+	scores := []map[string]float64{
+		{x: 1, y: 1},
+		{x: 1, y: 1, z: 1},
+		{y: 2, z: 2},
+	}
+	for _, userScores := range scores {
+		user := pgtest.NewUser(t, pool)
+		for category, score := range userScores {
+			insertLike(t, pool, user, category, score, now, "none")
+		}
+	}
+	// дизлайкнутые категории в модель не входят, иначе изменили бы и косинусы, и co_likes
+	unliked := pgtest.NewUser(t, pool)
+	insertLike(t, pool, unliked, x, 5, now, "unliked")
+	insertLike(t, pool, unliked, z, 5, now, "unliked")
+
+	cosXY := 2 / math.Sqrt(2*6)
+	cosXZ := 1 / math.Sqrt(2*5)
+	cosYZ := 5 / math.Sqrt(6*5)
+
+	type pair struct{ category, similar string }
+	tests := []struct {
+		name string
+		cfg  config.CollaborativeConfig
+		want map[pair]float64
+	}{
+		{
+			name: "pairs below min_co_likes are dropped",
+			cfg:  config.CollaborativeConfig{Neighbors: 10, MinCoLikes: 2, MaxUserCategories: 10},
+			want: map[pair]float64{{x, y}: cosXY, {y, x}: cosXY, {y, z}: cosYZ, {z, y}: cosYZ},
+		},
+		{
+			name: "single co-like is enough with min_co_likes 1",
+			cfg:  config.CollaborativeConfig{Neighbors: 10, MinCoLikes: 1, MaxUserCategories: 10},
+			want: map[pair]float64{
+				{x, y}: cosXY, {y, x}: cosXY, {y, z}: cosYZ, {z, y}: cosYZ,
+				{x, z}: cosXZ, {z, x}: cosXZ,
+			},
+		},
+		{
+			name: "only the closest neighbors are kept",
+			cfg:  config.CollaborativeConfig{Neighbors: 1, MinCoLikes: 1, MaxUserCategories: 10},
+			want: map[pair]float64{{x, y}: cosXY, {y, z}: cosYZ, {z, y}: cosYZ},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewSimilarityRepository(pool)
+			if _, err := repo.Rebuild(context.Background(), &tt.cfg); err != nil {
+				t.Fatal(err)
+			}
+
+			rows, err := pool.Query(context.Background(),
+				`SELECT category, similar, score FROM category_similarity WHERE category = ANY($1)`,
+				[]string{x, y, z})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make(map[pair]float64)
+			for rows.Next() {
+				var (
+					p     pair
+					score float64
+				)
+				if err := rows.Scan(&p.category, &p.similar, &score); err != nil {
+					t.Fatal(err)
+				}
+				got[p] = score
+			}
+			if err := rows.Err(); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Errorf("Rebuild() stored %d pairs, want %d: %v", len(got), len(tt.want), got)
+			}
+			for p, want := range tt.want {
+				if score, ok := got[p]; !ok || !approxEqual(score, want) {
+					t.Errorf("similarity %s → %s = %.4f (stored %v), want %.4f", p.category, p.similar, score, ok, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	entity2 "github.com/NordCoder/Story/internal/entity"
+	"github.com/NordCoder/Story/services/recommendation/config"
+	recentity "github.com/NordCoder/Story/services/recommendation/entity"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TrendingRepository считает вовлечённость всех пользователей по категориям.
+type TrendingRepository interface {
+	// Scores возвращает до limit категорий с наибольшим положительным скором сигналов с момента since.
+	Scores(ctx context.Context, since time.Time, weights config.TrendingWeights, limit int) ([]recentity.TrendingCategory, error)
+}
+
+type trendingRepositoryImpl struct {
+	db *pgxpool.Pool
+}
+
+func NewTrendingRepository(pool *pgxpool.Pool) TrendingRepository {
+	return &trendingRepositoryImpl{db: pool}
+}
+
+// Scores складывает три источника. Лайки берутся из user_category_likes: поштучной истории у них нет,
+// поэтому засчитывается каждый пользователь, чей интерес к категории положителен и менялся в окне.
+// Выдачи — из facts_archive по last_served_at: факт, выданный в окне несколько раз, считается однажды.
+func (r trendingRepositoryImpl) Scores(ctx context.Context, since time.Time, weights config.TrendingWeights, limit int) ([]recentity.TrendingCategory, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT category, SUM(score) AS score FROM (
+			SELECT category, $2::float8 * COUNT(*) AS score
+			FROM user_category_likes
+			WHERE updated_at >= $1 AND score > 0
+			GROUP BY category
+		UNION ALL
+			SELECT category, SUM(CASE reaction
+				WHEN 'like'    THEN $3::float8
+				WHEN 'dislike' THEN $4::float8
+				WHEN 'skip'    THEN $5::float8
+				ELSE 0 END) AS score
+			FROM fact_reactions
+			WHERE created_at >= $1
+			GROUP BY category
+		UNION ALL
+			SELECT category, $6::float8 * COUNT(*) AS score
+			FROM facts_archive
+			WHERE last_served_at >= $1
+			GROUP BY category
+		) s
+		WHERE category <> ''
+		GROUP BY category
+		HAVING SUM(score) > 0
+		ORDER BY score DESC
+		LIMIT $7`,
+		since, weights.Like, weights.ReactionLike, weights.ReactionDislike, weights.ReactionSkip, weights.Serve, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var scores []recentity.TrendingCategory
+	for rows.Next() {
+		var (
+			c     string
+			score float64
+		)
+		if err := rows.Scan(&c, &score); err != nil {
+			return nil, err
+		}
+		scores = append(scores, recentity.TrendingCategory{Category: entity2.Category(c), Score: score})
+	}
+	return scores, rows.Err()
+}
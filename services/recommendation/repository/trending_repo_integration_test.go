@@ -0,0 +1,96 @@
+//go:build integration_test
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	entity2 "github.com/NordCoder/Story/internal/entity"
+	"github.com/NordCoder/Story/internal/infrastructure/pgtest"
+	"github.com/NordCoder/Story/services/recommendation/config"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func insertReaction(t *testing.T, pool *pgxpool.Pool, category, reaction string, at time.Time) {
+	t.Helper()
+	user := pgtest.NewUser(t, pool)
+	pgtest.Exec(t, pool,
+		`INSERT INTO fact_reactions (user_id, fact_id, category, reaction, created_at) VALUES ($1, $2, $3, $4, $5)`,
+		string(user), string(entity2.NewFactID()), category, reaction, at)
+}
+
+func insertServedFact(t *testing.T, pool *pgxpool.Pool, category string, servedAt time.Time) {
+	t.Helper()
+	id := entity2.NewFactID()
+	pgtest.Exec(t, pool,
+		`INSERT INTO facts_archive (id, category, title, summary, source_url, fetched_at, serve_count, last_served_at)
+		 VALUES ($1, $2, 'title', 'summary', $3, NOW(), 1, $4)`,
+		string(id), category, "https://example.org/"+string(id), servedAt)
+	t.Cleanup(func() { pgtest.Exec(t, pool, `DELETE FROM facts_archive WHERE id = $1`, string(id)) })
+}
+
+func TestTrendingScoresCountOnlySignalsInsideWindow(t *testing.T) {
+	pool := pgtest.NewPool(t)
+	now := time.Now()
+	recent, old := now.Add(-30*time.Minute), now.Add(-24*time.Hour)
+	likes, reactions, serves, disliked := pgtest.Name("likes"), pgtest.Name("reactions"), pgtest.Name("serves"), pgtest.Name("disliked")
+
+	insertLike(t, pool, pgtest.NewUser(t, pool), likes, 2, recent, "liked")
+	insertLike(t, pool, pgtest.NewUser(t, pool), likes, 1, old, "liked")
+	insertLike(t, pool, pgtest.NewUser(t, pool), likes, 0, recent, "none") // нулевой интерес не считается
+
+	insertReaction(t, pool, reactions, "like", recent)
+	insertReaction(t, pool, reactions, "skip", recent)
+	insertReaction(t, pool, reactions, "dislike", old)
+
+	insertServedFact(t, pool, serves, recent)
+	insertServedFact(t, pool, serves, old)
+
+	insertReaction(t, pool, disliked, "dislike", recent)
+
+	weights := config.TrendingWeights{Like: 1, ReactionLike: 2, ReactionDislike: -3, ReactionSkip: -0.5, Serve: 0.25}
+	tests := []struct {
+		name   string
+		window time.Duration
+		want   map[string]float64
+	}{
+		{
+			name:   "hour",
+			window: time.Hour,
+			want:   map[string]float64{likes: 1, reactions: 1.5, serves: 0.25},
+		},
+		{
+			// старый дизлайк уводит реакции в минус, и категория выпадает из трендов
+			name:   "two days",
+			window: 48 * time.Hour,
+			want:   map[string]float64{likes: 2, serves: 0.5},
+		},
+	}
+	repo := NewTrendingRepository(pool)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scores, err := repo.Scores(context.Background(), now.Add(-tt.window), weights, 10000)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make(map[string]float64)
+			for _, s := range scores {
+				switch string(s.Category) {
+				case likes, reactions, serves, disliked:
+					got[string(s.Category)] = s.Score
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("Scores() for the test categories = %v, want %v", got, tt.want)
+			}
+			for category, want := range tt.want {
+				if score, ok := got[category]; !ok || !approxEqual(score, want) {
+					t.Errorf("score of %s = %.4f (present %v), want %.4f", category, score, ok, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	entity2 "github.com/NordCoder/Story/internal/entity"
+	recentity "github.com/NordCoder/Story/services/recommendation/entity"
+	"github.com/go-redis/redis/v8"
+)
+
+// TrendingStore хранит готовые тренды в Redis: по sorted set на окно, скор — вовлечённость.
+type TrendingStore interface {
+	// Replace атомарно подменяет тренды окна; ttl защищает от выдачи устаревших данных,
+	// если агрегатор перестал работать.
+	Replace(ctx context.Context, window string, scores []recentity.TrendingCategory, ttl time.Duration) error
+	// Top возвращает до limit категорий окна по убыванию скора.
+	Top(ctx context.Context, window string, limit int) ([]recentity.TrendingCategory, error)
+	// TrendingCategories — то же, что Top, без скоров; этим методом стор служит провайдеру категорий.
+	TrendingCategories(ctx context.Context, window string, limit int) ([]entity2.Category, error)
+}
+
+type trendingStoreImpl struct {
+	client    redis.UniversalClient
+	keyPrefix string
+}
+
+func NewTrendingStore(client redis.UniversalClient) TrendingStore {
+	return &trendingStoreImpl{client: client, keyPrefix: "trending:"}
+}
+
+// key берёт окно в hash tag: Replace переименовывает временный ключ окна в основной
+// внутри MULTI, и в кластере оба должны быть в одном слоте, а разные окна — нет.
+func (s *trendingStoreImpl) key(window string) string {
+	return s.keyPrefix + "{" + window + "}"
+}
+
+func (s *trendingStoreImpl) Replace(ctx context.Context, window string, scores []recentity.TrendingCategory, ttl time.Duration) error {
+	key := s.key(window)
+	if len(scores) == 0 {
+		return s.client.Del(ctx, key).Err()
+	}
+
+	members := make([]*redis.Z, len(scores))
+	for i, sc := range scores {
+		members[i] = &redis.Z{Score: sc.Score, Member: string(sc.Category)}
+	}
+	tmp := key + ":tmp"
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, tmp)
+		pipe.ZAdd(ctx, tmp, members...)
+		pipe.Expire(ctx, tmp, ttl)
+		pipe.Rename(ctx, tmp, key)
+		return nil
+	})
+	return err
+}
+
+func (s *trendingStoreImpl) Top(ctx context.Context, window string, limit int) ([]recentity.TrendingCategory, error) {
+	zs, err := s.client.ZRevRangeWithScores(ctx, s.key(window), 0, int64(limit)-1).Result()
+	if err != nil {
+		return nil, err
+	}
+	scores := make([]recentity.TrendingCategory, 0, len(zs))
+	for _, z := range zs {
+		member, _ := z.Member.(string)
+		scores = append(scores, recentity.TrendingCategory{Category: entity2.Category(member), Score: z.Score})
+	}
+	return scores, nil
+}
+
+func (s *trendingStoreImpl) TrendingCategories(ctx context.Context, window string, limit int) ([]entity2.Category, error) {
+	members, err := s.client.ZRevRange(ctx, s.key(window), 0, int64(limit)-1).Result()
+	if err != nil {
+		return nil, err
+	}
+	categories := make([]entity2.Category, len(members))
+	for i, m := range members {
+		categories[i] = entity2.Category(m)
+	}
+	return categories, nil
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	entity2 "github.com/NordCoder/Story/internal/entity"
+	recentity "github.com/NordCoder/Story/services/recommendation/entity"
+)
+
+// memoryTrendingStore хранит тренды в памяти процесса — для запуска без Redis.
+// Replace получает скоры уже отсортированными по убыванию, как их отдаёт TrendingRepository.Scores.
+type memoryTrendingStore struct {
+	mu      sync.RWMutex
+	now     func() time.Time
+	windows map[string]memoryTrends
+}
+
+type memoryTrends struct {
+	scores    []recentity.TrendingCategory
+	expiresAt time.Time
+}
+
+func NewMemoryTrendingStore() TrendingStore {
+	return &memoryTrendingStore{now: time.Now, windows: make(map[string]memoryTrends)}
+}
+
+func (s *memoryTrendingStore) Replace(_ context.Context, window string, scores []recentity.TrendingCategory, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(scores) == 0 {
+		delete(s.windows, window)
+		return nil
+	}
+	s.windows[window] = memoryTrends{
+		scores:    append([]recentity.TrendingCategory(nil), scores...),
+		expiresAt: s.now().Add(ttl),
+	}
+	return nil
+}
+
+func (s *memoryTrendingStore) Top(_ context.Context, window string, limit int) ([]recentity.TrendingCategory, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	t, ok := s.windows[window]
+	if !ok || !s.now().Before(t.expiresAt) {
+		return nil, nil
+	}
+	scores := t.scores
+	if len(scores) > limit {
+		scores = scores[:limit]
+	}
+	return append([]recentity.TrendingCategory(nil), scores...), nil
+}
+
+func (s *memoryTrendingStore) TrendingCategories(ctx context.Context, window string, limit int) ([]entity2.Category, error) {
+	scores, err := s.Top(ctx, window, limit)
+	if err != nil {
+		return nil, err
+	}
+	categories := make([]entity2.Category, len(scores))
+	for i, sc := range scores {
+		categories[i] = sc.Category
+	}
+	return categories, nil
+}
@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	entity2 "github.com/NordCoder/Story/internal/entity"
+	"github.com/NordCoder/Story/internal/weighted"
+	"github.com/NordCoder/Story/services/authorization/entity"
+	"github.com/NordCoder/Story/services/recommendation/bandit"
+	"github.com/NordCoder/Story/services/recommendation/config"
+	recentity "github.com/NordCoder/Story/services/recommendation/entity"
+	"github.com/NordCoder/Story/services/recommendation/repository"
+)
+
+// fakeBanditStore отдаёт заранее заданную статистику или err.
+type fakeBanditStore struct {
+	repository.BanditStore
+	stats map[entity2.Category]repository.BanditStats
+	err   error
+}
+
+func (s fakeBanditStore) Stats(context.Context, entity.UserID, []entity2.Category) (map[entity2.Category]repository.BanditStats, error) {
+	return s.stats, s.err
+}
+
+func newBanditUseCase(store repository.BanditStore, rankBonus float64, seed int64) RecUseCaseImpl {
+	cfg := &config.BanditConfig{Enabled: true, PriorAlpha: 1, PriorBeta: 1, RankBonus: rankBonus, StateTTL: time.Hour}
+	uc := NewRecUseCase(nil, nil, nil, WithBandit(store, bandit.NewThompson(bandit.WithRand(weighted.NewSeededRand(seed))), cfg))
+	return *uc.(*RecUseCaseImpl)
+}
+
+func testCandidates(categories ...entity2.Category) []recentity.RecommendedCategory {
+	candidates := make([]recentity.RecommendedCategory, len(categories))
+	for i, c := range categories {
+		candidates[i] = recentity.RecommendedCategory{Category: c}
+	}
+	return candidates
+}
+
+// chooseCounts считает, сколько раз выбрана каждая категория за draws вызовов.
+func chooseCounts(uc RecUseCaseImpl, candidates []recentity.RecommendedCategory, draws int) map[entity2.Category]int {
+	counts := make(map[entity2.Category]int)
+	for i := 0; i < draws; i++ {
+		counts[uc.ChooseCategory(context.Background(), "user", candidates).Category]++
+	}
+	return counts
+}
+
+func TestChooseCategoryRankBonusFavoursTopCandidate(t *testing.T) {
+	candidates := testCandidates("top", "middle", "bottom")
+	const draws = 2000
+
+	withBonus := chooseCounts(newBanditUseCase(fakeBanditStore{}, 8, 11), candidates, draws)
+	if withBonus["top"] <= withBonus["middle"] || withBonus["middle"] <= withBonus["bottom"] {
+		t.Errorf("with rank bonus counts = %v, want top > middle > bottom", withBonus)
+	}
+	if withBonus["bottom"] == 0 {
+		t.Error("bottom candidate never chosen: rank bonus must not stop exploration")
+	}
+
+	// без бонуса и истории кандидаты равноправны
+	flat := chooseCounts(newBanditUseCase(fakeBanditStore{}, 0, 11), candidates, draws)
+	for c, n := range flat {
+		if n < draws/3-150 || n > draws/3+150 {
+			t.Errorf("without rank bonus %s chosen %d of %d times, want about a third", c, n, draws)
+		}
+	}
+}
+
+func TestChooseCategoryHistoryOutweighsRank(t *testing.T) {
+	store := fakeBanditStore{stats: map[entity2.Category]repository.BanditStats{
+		"top":    {Failures: 40},
+		"bottom": {Successes: 40},
+	}}
+	counts := chooseCounts(newBanditUseCase(store, 2, 5), testCandidates("top", "bottom"), 1000)
+	if counts["bottom"] < 900 {
+		t.Errorf("counts = %v, want the rewarded bottom candidate to win at least 90%%", counts)
+	}
+}
+
+func TestChooseCategoryFallsBackToFirst(t *testing.T) {
+	uc := newBanditUseCase(fakeBanditStore{err: errors.New("redis is down")}, 1, 1)
+	candidates := testCandidates("top", "other")
+	for i := 0; i < 20; i++ {
+		if got := uc.ChooseCategory(context.Background(), "user", candidates); got.Category != "top" {
+			t.Fatalf("ChooseCategory() = %s on store error, want the first candidate", got.Category)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	entity2 "github.com/NordCoder/Story/internal/entity"
+	recentity "github.com/NordCoder/Story/services/recommendation/entity"
+)
+
+// defaultRecSize — размер выдачи GetUserRec, если cold start не настроен.
+const defaultRecSize = 10
+
+// blend собирает выдачу из нескольких источников по приоритету, без повторов.
+type blend struct {
+	size  int
+	items []recentity.RecommendedCategory
+	seen  map[entity2.Category]struct{}
+}
+
+func newBlend(size int) *blend {
+	return &blend{
+		size:  size,
+		items: make([]recentity.RecommendedCategory, 0, size),
+		seen:  make(map[entity2.Category]struct{}, size),
+	}
+}
+
+// add дописывает категории источника, пока выдача не заполнится.
+func (b *blend) add(categories []recentity.ScoredCategory, source recentity.CategorySource) {
+	b.addN(categories, source, b.size)
+}
+
+// addN дописывает не больше n новых категорий источника и возвращает, сколько дописано.
+func (b *blend) addN(categories []recentity.ScoredCategory, source recentity.CategorySource, n int) int {
+	added := 0
+	for _, c := range categories {
+		if b.full() || added >= n {
+			return added
+		}
+		if _, ok := b.seen[c.Category]; ok {
+			continue
+		}
+		b.seen[c.Category] = struct{}{}
+		b.items = append(b.items, recentity.RecommendedCategory{
+			Category: c.Category,
+			Source:   source,
+			Score:    c.Score,
+			Via:      c.Via,
+		})
+		added++
+	}
+	return added
+}
+
+// exclude запрещает категории: add и addN будут их пропускать.
+func (b *blend) exclude(categories []entity2.Category) {
+	for _, c := range categories {
+		b.seen[c] = struct{}{}
+	}
+}
+
+func (b *blend) full() bool { return len(b.items) >= b.size }
+
+// unscored превращает список категорий без скоров (например, onboarding) в кандидатов с нулевым скором.
+func unscored(categories []entity2.Category) []recentity.ScoredCategory {
+	scored := make([]recentity.ScoredCategory, len(categories))
+	for i, c := range categories {
+		scored[i] = recentity.ScoredCategory{Category: c}
+	}
+	return scored
+}
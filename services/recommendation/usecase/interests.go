@@ -0,0 +1,214 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	entity2 "github.com/NordCoder/Story/internal/entity"
+	"github.com/NordCoder/Story/internal/infrastructure/wikipedia"
+	"github.com/NordCoder/Story/internal/logger"
+	"github.com/NordCoder/Story/services/recommendation/config"
+	recentity "github.com/NordCoder/Story/services/recommendation/entity"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrUnknownInterest возвращается SetInterests для категории не из каталога онбординга.
+var ErrUnknownInterest = errors.New("category is not an interest topic")
+
+// sampleArticles — сколько статей темы просматривать в поисках миниатюры.
+const sampleArticles = 5
+
+const (
+	// refreshTimeout ограничивает одно обновление каталога.
+	refreshTimeout = 30 * time.Second
+	// refreshRetry — через сколько повторить обновление, в котором Википедия ответила не на всё.
+	refreshRetry = time.Minute
+)
+
+// errPartialRefresh — Википедия ответила не на все запросы обновления.
+var errPartialRefresh = errors.New("interests: some wikipedia requests failed")
+
+// InterestCatalog собирает темы онбординга из конфига и подкатегорий Википедии.
+// Topics и Contains отдают закэшированный каталог и в Википедию не ходят: его обновляет Run
+// раз в refresh_interval. Если часть запросов не удалась, темы сохраняют данные прошлого
+// обновления, а следующая попытка делается через refreshRetry. До первого обновления
+// каталог состоит из тем конфига без подкатегорий.
+type InterestCatalog struct {
+	cfg  *config.InterestsConfig
+	wiki wikipedia.WikiClient
+
+	refreshes singleflight.Group // одновременные Refresh делят один проход по Википедии
+	mu        sync.RWMutex
+	enriched  map[entity2.Category]enrichedTopic
+	allowed   map[entity2.Category]struct{}
+}
+
+type enrichedTopic struct {
+	imageURL  string
+	subtopics []entity2.Category
+}
+
+func NewInterestCatalog(cfg *config.InterestsConfig, wiki wikipedia.WikiClient) *InterestCatalog {
+	c := &InterestCatalog{
+		cfg:      cfg,
+		wiki:     wiki,
+		enriched: make(map[entity2.Category]enrichedTopic, len(cfg.Topics)),
+		allowed:  make(map[entity2.Category]struct{}, len(cfg.Topics)),
+	}
+	for _, t := range cfg.Topics {
+		c.enriched[t.Category] = enrichedTopic{imageURL: t.ImageURL}
+		c.allowed[t.Category] = struct{}{}
+	}
+	return c
+}
+
+// Topics возвращает темы с названиями на языке lang (или default_lang, если перевода нет).
+func (c *InterestCatalog) Topics(_ context.Context, lang string) []recentity.InterestTopic {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	topics := make([]recentity.InterestTopic, 0, len(c.cfg.Topics))
+	for _, t := range c.cfg.Topics {
+		e := c.enriched[t.Category]
+		topic := recentity.InterestTopic{
+			Category: t.Category,
+			Title:    c.title(t, lang),
+			ImageURL: e.imageURL,
+		}
+		for _, sub := range e.subtopics {
+			topic.Subtopics = append(topic.Subtopics, recentity.InterestTopic{
+				Category: sub,
+				Title:    displayName(sub),
+			})
+		}
+		topics = append(topics, topic)
+	}
+	return topics
+}
+
+// Contains сообщает, есть ли категория среди тем или их подкатегорий.
+func (c *InterestCatalog) Contains(_ context.Context, category entity2.Category) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, ok := c.allowed[category]
+	return ok
+}
+
+// Run обновляет каталог сразу и затем раз в refresh_interval, после частичной неудачи —
+// через refreshRetry. Блокирует до отмены ctx.
+func (c *InterestCatalog) Run(ctx context.Context) {
+	timer := time.NewTimer(c.refresh(ctx))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			timer.Reset(c.refresh(ctx))
+		}
+	}
+}
+
+// refresh выполняет Refresh и возвращает паузу до следующего обновления.
+func (c *InterestCatalog) refresh(ctx context.Context) time.Duration {
+	if err := c.Refresh(ctx); err != nil {
+		logger.LoggerFromContext(ctx).Warn("interests: catalog refresh incomplete", zap.Error(err))
+		return refreshRetry
+	}
+	return c.cfg.RefreshInterval
+}
+
+// Refresh заново запрашивает подкатегории и миниатюры тем и подменяет каталог.
+// Темы, по которым Википедия не ответила, сохраняют прежние данные; тогда возвращается ошибка.
+func (c *InterestCatalog) Refresh(ctx context.Context) error {
+	_, err, _ := c.refreshes.Do("refresh", func() (interface{}, error) {
+		return nil, c.load(ctx)
+	})
+	return err
+}
+
+func (c *InterestCatalog) load(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, refreshTimeout)
+	defer cancel()
+
+	c.mu.RLock()
+	previous := c.enriched
+	c.mu.RUnlock()
+
+	failed := false
+	enriched := make(map[entity2.Category]enrichedTopic, len(c.cfg.Topics))
+	allowed := make(map[entity2.Category]struct{})
+	for _, t := range c.cfg.Topics {
+		allowed[t.Category] = struct{}{}
+		e := enrichedTopic{imageURL: t.ImageURL}
+		prev := previous[t.Category]
+
+		if c.cfg.SubcatLimit > 0 {
+			subs, err := c.wiki.GetSubcategories(ctx, t.Category, c.cfg.SubcatLimit)
+			if err != nil {
+				logger.LoggerFromContext(ctx).Warn("interests: failed to get subcategories", zap.String("category", string(t.Category)), zap.Error(err))
+				failed = true
+				subs = prev.subtopics
+			}
+			e.subtopics = subs
+			for _, sub := range subs {
+				allowed[sub] = struct{}{}
+			}
+		}
+
+		if e.imageURL == "" {
+			image, err := c.sampleImage(ctx, t.Category)
+			if err != nil {
+				logger.LoggerFromContext(ctx).Warn("interests: failed to get sample image", zap.String("category", string(t.Category)), zap.Error(err))
+				failed = true
+				image = prev.imageURL
+			}
+			e.imageURL = image
+		}
+		enriched[t.Category] = e
+	}
+
+	c.mu.Lock()
+	c.enriched = enriched
+	c.allowed = allowed
+	c.mu.Unlock()
+
+	if failed {
+		return errPartialRefresh
+	}
+	return nil
+}
+
+// sampleImage возвращает миниатюру первой статьи категории, у которой она есть.
+func (c *InterestCatalog) sampleImage(ctx context.Context, category entity2.Category) (string, error) {
+	summaries, err := c.wiki.GetCategorySummaries(ctx, category, sampleArticles)
+	if err != nil {
+		return "", err
+	}
+	for _, s := range summaries {
+		if s.ImageURL != "" {
+			return s.ImageURL, nil
+		}
+	}
+	return "", nil
+}
+
+func (c *InterestCatalog) title(t config.InterestTopic, lang string) string {
+	if title, ok := t.Titles[lang]; ok {
+		return title
+	}
+	if title, ok := t.Titles[c.cfg.DefaultLang]; ok {
+		return title
+	}
+	return displayName(t.Category)
+}
+
+// displayName превращает заголовок категории Википедии в читаемое название.
+func displayName(category entity2.Category) string {
+	return strings.ReplaceAll(string(category), "_", " ")
+}
@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	entity2 "github.com/NordCoder/Story/internal/entity"
+	"github.com/NordCoder/Story/internal/infrastructure/wikipedia"
+	"github.com/NordCoder/Story/services/recommendation/config"
+)
+
+// fakeWiki отвечает подкатегориями из subs, пока fail не выставлен. Если задан release,
+// GetSubcategories ждёт его закрытия.
+type fakeWiki struct {
+	wikipedia.WikiClient
+
+	mu      sync.Mutex
+	fail    bool
+	calls   int
+	release chan struct{}
+	subs    []entity2.Category
+}
+
+func (w *fakeWiki) GetSubcategories(ctx context.Context, _ entity2.Category, _ int) ([]entity2.Category, error) {
+	w.mu.Lock()
+	w.calls++
+	release := w.release
+	w.mu.Unlock()
+	if release != nil {
+		<-release
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.fail {
+		return nil, errors.New("wikipedia is down")
+	}
+	return w.subs, nil
+}
+
+func (w *fakeWiki) GetCategorySummaries(ctx context.Context, _ entity2.Category, _ int) ([]*wikipedia.ArticleSummary, error) {
+	return []*wikipedia.ArticleSummary{{ImageURL: "https://upload.wikimedia.org/x.jpg"}}, nil
+}
+
+func newTestCatalog(wiki *fakeWiki) *InterestCatalog {
+	cfg := &config.InterestsConfig{
+		DefaultLang:     "ru",
+		SubcatLimit:     3,
+		RefreshInterval: time.Hour,
+		Topics:          []config.InterestTopic{{Category: "История"}},
+	}
+	return NewInterestCatalog(cfg, wiki)
+}
+
+func TestCatalogServesCacheWithoutWikipedia(t *testing.T) {
+	wiki := &fakeWiki{subs: []entity2.Category{"Древний_мир"}}
+	c := newTestCatalog(wiki)
+	ctx := context.Background()
+
+	// до первого обновления — только темы конфига
+	if !c.Contains(ctx, "История") || c.Contains(ctx, "Древний_мир") {
+		t.Error("catalog before the first refresh must hold exactly the configured topics")
+	}
+	if topics := c.Topics(ctx, "ru"); len(topics) != 1 || len(topics[0].Subtopics) != 0 {
+		t.Errorf("Topics() = %+v, want the configured topic without subtopics", topics)
+	}
+	if wiki.calls != 0 {
+		t.Errorf("Topics/Contains called wikipedia %d times, want 0", wiki.calls)
+	}
+
+	if err := c.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Contains(ctx, "Древний_мир") {
+		t.Error("subcategory is missing after refresh")
+	}
+}
+
+func TestFailedRefreshKeepsPreviousData(t *testing.T) {
+	wiki := &fakeWiki{subs: []entity2.Category{"Древний_мир"}}
+	c := newTestCatalog(wiki)
+	ctx := context.Background()
+
+	if err := c.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	wiki.fail = true
+	if err := c.Refresh(ctx); !errors.Is(err, errPartialRefresh) {
+		t.Errorf("Refresh() error = %v, want errPartialRefresh", err)
+	}
+	if !c.Contains(ctx, "Древний_мир") {
+		t.Error("failed refresh dropped subcategories from the previous one")
+	}
+	if next := c.refresh(ctx); next != refreshRetry {
+		t.Errorf("next refresh after a failure in %s, want refreshRetry", next)
+	}
+}
+
+func TestConcurrentRefreshesShareOneRequest(t *testing.T) {
+	wiki := &fakeWiki{subs: []entity2.Category{"Древний_мир"}, release: make(chan struct{})}
+	c := newTestCatalog(wiki)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.Refresh(ctx); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	// ждём, пока первый Refresh дойдёт до Википедии, и даём остальным к нему присоединиться
+	for {
+		wiki.mu.Lock()
+		calls := wiki.calls
+		wiki.mu.Unlock()
+		if calls > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(wiki.release)
+	wg.Wait()
+
+	if wiki.calls != 1 {
+		t.Errorf("wikipedia called %d times, want 1", wiki.calls)
+	}
+}
@@ -2,23 +2,46 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
 
 	entity2 "github.com/NordCoder/Story/internal/entity"
+	"github.com/NordCoder/Story/internal/infrastructure"
 
 	"github.com/NordCoder/Story/internal/logger"
 	"github.com/NordCoder/Story/services/authorization/entity"
+	"github.com/NordCoder/Story/services/recommendation/bandit"
+	"github.com/NordCoder/Story/services/recommendation/config"
+	recentity "github.com/NordCoder/Story/services/recommendation/entity"
 	"github.com/NordCoder/Story/services/recommendation/repository"
 	"go.uber.org/zap"
 )
 
-type propagateTask struct {
-	userID   entity.UserID
-	category entity2.Category
-	depth    int
+// reactionWeights — на сколько реакция на факт сдвигает счётчик его категории.
+var reactionWeights = map[recentity.ReactionKind]float64{
+	recentity.ReactionLike:    2,
+	recentity.ReactionDislike: -2,
+	recentity.ReactionSkip:    -1,
 }
 
-// buffered channel for background propagation tasks
-var propagateCh = make(chan propagateTask, 1000)
+// likeWeight — на сколько явный лайк поднимает скор категории (и дизлайк опускает).
+const likeWeight = 1
+
+// banditRewards — награда бандиту за реакцию на факт категории; долгий пропуск — см. ReactToFact.
+var banditRewards = map[recentity.ReactionKind]float64{
+	recentity.ReactionLike:    1,
+	recentity.ReactionDislike: 0,
+	recentity.ReactionSkip:    0,
+}
+
+// readRewardFloor — награда за прочитанный (по readDwell), но не лайкнутый и не дизлайкнутый факт.
+const readRewardFloor = 0.5
+
+// readDwell — сколько факт должен провисеть на экране, чтобы считаться прочитанным:
+// прочтение добавляет категории +1 независимо от реакции, так что долгий пропуск нейтрален.
+const readDwell = 15 * time.Second
 
 // default zatychka
 
@@ -27,37 +50,158 @@ var propagateCh = make(chan propagateTask, 1000)
 type RecUseCase interface {
 	LikeCategory(context.Context, entity.UserID, entity2.Category) error
 	UnlikeCategory(context.Context, entity.UserID, entity2.Category) error
-	GetUserRec(ctx context.Context, id entity.UserID) ([]entity2.Category, error)
+	GetUserRec(ctx context.Context, id entity.UserID) ([]recentity.RecommendedCategory, error)
+	// ChooseCategory выбирает, из какой рекомендованной категории показать факт; candidates не пуст.
+	ChooseCategory(ctx context.Context, id entity.UserID, candidates []recentity.RecommendedCategory) recentity.RecommendedCategory
+	ReactToFact(ctx context.Context, id entity.UserID, factID entity2.FactID, kind recentity.ReactionKind, dwell time.Duration) error
+	RecordEvents(ctx context.Context, id entity.UserID, events []recentity.Event) error
+	ListInterestTopics(ctx context.Context, lang string) ([]recentity.InterestTopic, error)
+	SetInterests(ctx context.Context, id entity.UserID, categories []entity2.Category) error
+	ListMyCategories(ctx context.Context, id entity.UserID, limit, offset int) ([]recentity.CategoryPreference, int, error)
+	ResetCategory(ctx context.Context, id entity.UserID, category entity2.Category) error
+	ClearPreferences(ctx context.Context, id entity.UserID) error
+	GetTrending(ctx context.Context, window string, limit int) (string, []recentity.TrendingCategory, error)
 }
 
+// Propagator распространяет лайк категории на её подкатегории в фоне; Enqueue не блокируется.
+type Propagator interface {
+	Enqueue(userID entity.UserID, category entity2.Category) bool
+}
+
+// EventSink принимает события на асинхронную запись; ошибка означает, что не принято ни одно.
+type EventSink interface {
+	Enqueue(events []recentity.Event) error
+}
+
+var (
+	// ErrEventsDisabled возвращается RecordEvents, если приём событий не настроен.
+	ErrEventsDisabled = errors.New("event ingestion is disabled")
+	// ErrTooManyEvents возвращается, если в запросе больше событий, чем разрешено конфигом.
+	ErrTooManyEvents = errors.New("too many events in one request")
+	// ErrUnknownWindow возвращается GetTrending для окна, которого нет в конфиге трендов.
+	ErrUnknownWindow = errors.New("unknown trending window")
+	// ErrTrendingDisabled возвращается GetTrending, если тренды не настроены.
+	ErrTrendingDisabled = errors.New("trending is disabled")
+)
+
 type RecUseCaseImpl struct {
-	recRepo repository.RecRepository
+	recRepo      repository.RecRepository
+	reactionRepo repository.ReactionRepository
+	factRepo     infrastructure.FactRepository
+
+	propagator Propagator
+	coldStart  *config.ColdStartConfig
+	interests  *InterestCatalog
+	// interestWeight — начальный скор темы, выбранной в SetInterests.
+	interestWeight float64
+
+	banditStore repository.BanditStore
+	bandit      *bandit.Thompson
+	banditCfg   *config.BanditConfig
+
+	similarRepo  repository.SimilarityRepository
+	similarShare float64
+
+	trendingStore repository.TrendingStore
+	trendingCfg   *config.TrendingConfig
+
+	eventSink    EventSink
+	eventRepo    repository.EventRepository
+	maxEvents    int
+	signalWindow time.Duration
+}
+
+type Option func(*RecUseCaseImpl)
+
+// WithInterests включает онбординг: каталог тем и начальный скор выбранных.
+func WithInterests(catalog *InterestCatalog, weight float64) Option {
+	return func(r *RecUseCaseImpl) {
+		r.interests = catalog
+		r.interestWeight = weight
+	}
+}
+
+// WithBandit включает выбор категории сэмплированием Томпсона с наградами из ReactToFact.
+func WithBandit(store repository.BanditStore, selector *bandit.Thompson, cfg *config.BanditConfig) Option {
+	return func(r *RecUseCaseImpl) {
+		r.banditStore = store
+		r.bandit = selector
+		r.banditCfg = cfg
+	}
+}
+
+// WithSimilar подмешивает в GetUserRec категории, похожие на лайкнутые, долей share от выдачи.
+func WithSimilar(repo repository.SimilarityRepository, share float64) Option {
+	return func(r *RecUseCaseImpl) {
+		r.similarRepo = repo
+		r.similarShare = share
+	}
+}
+
+// WithTrending включает GetTrending поверх трендов, посчитанных агрегатором.
+func WithTrending(store repository.TrendingStore, cfg *config.TrendingConfig) Option {
+	return func(r *RecUseCaseImpl) {
+		r.trendingStore = store
+		r.trendingCfg = cfg
+	}
+}
+
+// WithColdStart задаёт размер выдачи GetUserRec и стартовый набор категорий.
+func WithColdStart(cfg *config.ColdStartConfig) Option {
+	return func(r *RecUseCaseImpl) { r.coldStart = cfg }
+}
+
+// WithPropagator включает распространение лайков на подкатегории.
+func WithPropagator(p Propagator) Option {
+	return func(r *RecUseCaseImpl) { r.propagator = p }
+}
+
+// WithEvents включает приём неявных сигналов в sink и их учёт в GetUserRec за последние window.
+func WithEvents(sink EventSink, repo repository.EventRepository, maxPerRequest int, window time.Duration) Option {
+	return func(r *RecUseCaseImpl) {
+		r.eventSink = sink
+		r.eventRepo = repo
+		r.maxEvents = maxPerRequest
+		r.signalWindow = window
+	}
 }
 
-func NewRecUseCase(recRepo repository.RecRepository) RecUseCase {
-	return &RecUseCaseImpl{
-		recRepo: recRepo,
+func NewRecUseCase(recRepo repository.RecRepository, reactionRepo repository.ReactionRepository, factRepo infrastructure.FactRepository, opts ...Option) RecUseCase {
+	uc := &RecUseCaseImpl{
+		recRepo:      recRepo,
+		reactionRepo: reactionRepo,
+		factRepo:     factRepo,
 	}
+	for _, o := range opts {
+		o(uc)
+	}
+	return uc
 }
 
+// LikeCategory идемпотентен: повторный лайк не поднимает скор и не распространяется заново.
 func (r RecUseCaseImpl) LikeCategory(ctx context.Context, id entity.UserID, category entity2.Category) error {
 	logger.LoggerFromContext(ctx).Info("LikeCategory usecase starts", zap.String("category: ", string(category)))
-	err := r.recRepo.Adjust(ctx, id, category, 1)
+	changed, err := r.recRepo.SetLikeState(ctx, id, category, recentity.LikeLiked, likeWeight)
 	if err != nil {
 		logger.LoggerFromContext(ctx).Error("Incr Error", zap.Error(err))
 		return err
 	}
-	select {
-	case propagateCh <- propagateTask{userID: id, category: category, depth: 1}:
-	default:
-		logger.LoggerFromContext(ctx).Warn("propagateCh full, dropping propagation task", zap.String("category", string(category)), zap.String("user_id", string(id)))
+	if changed {
+		r.propagate(id, category)
 	}
 	return nil
 }
 
+func (r RecUseCaseImpl) propagate(id entity.UserID, category entity2.Category) {
+	if r.propagator != nil {
+		r.propagator.Enqueue(id, category)
+	}
+}
+
+// UnlikeCategory идемпотентен, как и LikeCategory; скор не опускается ниже нуля.
 func (r RecUseCaseImpl) UnlikeCategory(ctx context.Context, id entity.UserID, category entity2.Category) error {
 	logger.LoggerFromContext(ctx).Info("UnlikeCategory usecase starts", zap.String("category: ", string(category)))
-	err := r.recRepo.Adjust(ctx, id, category, -1)
+	_, err := r.recRepo.SetLikeState(ctx, id, category, recentity.LikeUnliked, -likeWeight)
 	if err != nil {
 		logger.LoggerFromContext(ctx).Error("Decrement Error", zap.Error(err))
 		return err
@@ -65,12 +209,295 @@ func (r RecUseCaseImpl) UnlikeCategory(ctx context.Context, id entity.UserID, ca
 	return nil
 }
 
-func (r RecUseCaseImpl) GetUserRec(ctx context.Context, id entity.UserID) ([]entity2.Category, error) {
+// GetUserRec возвращает до cold_start.size категорий. Собственные данные пользователя идут первыми,
+// но долю collaborative.share резервируют категории, похожие на лайкнутые; остаток добирается
+// популярными и стартовыми категориями. Явно дизлайкнутые категории не попадают в выдачу ни из какого
+// источника. Ошибка источника не прерывает сборку: выдача просто станет короче.
+// ErrNotEnoughData — только если не нашлось ни одной категории.
+func (r RecUseCaseImpl) GetUserRec(ctx context.Context, id entity.UserID) ([]recentity.RecommendedCategory, error) {
 	logger.LoggerFromContext(ctx).Info("GetUserRec usecase starts")
-	categories, err := r.recRepo.TopCategories(ctx, id)
+
+	size := defaultRecSize
+	var onboarding []entity2.Category
+	if r.coldStart != nil {
+		size = r.coldStart.Size
+		onboarding = r.coldStart.Onboarding
+	}
+	b := newBlend(size)
+
+	unliked, err := r.recRepo.Unliked(ctx, id)
+	if err != nil {
+		logger.LoggerFromContext(ctx).Error("Unliked Error", zap.Error(err))
+	}
+	b.exclude(unliked)
+
+	likes, err := r.recRepo.TopCategories(ctx, id, size)
 	if err != nil {
 		logger.LoggerFromContext(ctx).Error("TopCategories Error", zap.Error(err))
-		return nil, err
 	}
-	return categories, nil
+
+	var similar []recentity.ScoredCategory
+	similarSlots := 0
+	if r.similarRepo != nil && r.similarShare > 0 {
+		similar, err = r.similarRepo.SimilarCategories(ctx, id, size)
+		if err != nil {
+			logger.LoggerFromContext(ctx).Error("SimilarCategories Error", zap.Error(err))
+		}
+		similarSlots = int(math.Round(float64(size) * r.similarShare))
+	}
+
+	b.addN(likes, recentity.SourceLikes, size-similarSlots)
+	b.addN(similar, recentity.SourceSimilar, similarSlots)
+	// свободные слоты одного источника отдаются другому
+	b.add(likes, recentity.SourceLikes)
+
+	if !b.full() && r.eventRepo != nil {
+		signals, err := r.eventRepo.TopSignalCategories(ctx, id, time.Now().Add(-r.signalWindow), size)
+		if err != nil {
+			logger.LoggerFromContext(ctx).Error("TopSignalCategories Error", zap.Error(err))
+		}
+		b.add(signals, recentity.SourceSignals)
+	}
+
+	b.add(similar, recentity.SourceSimilar)
+
+	if !b.full() {
+		popular, err := r.recRepo.PopularCategories(ctx, size)
+		if err != nil {
+			logger.LoggerFromContext(ctx).Error("PopularCategories Error", zap.Error(err))
+		}
+		b.add(popular, recentity.SourcePopular)
+	}
+
+	b.add(unscored(onboarding), recentity.SourceOnboarding)
+
+	if len(b.items) == 0 {
+		return nil, repository.ErrNotEnoughData
+	}
+	return b.items, nil
+}
+
+// ReactToFact сохраняет сырую реакцию и сдвигает счётчик категории факта на её вес.
+func (r RecUseCaseImpl) ReactToFact(ctx context.Context, id entity.UserID, factID entity2.FactID, kind recentity.ReactionKind, dwell time.Duration) error {
+	logger.LoggerFromContext(ctx).Info("ReactToFact usecase starts", zap.String("fact_id", string(factID)), zap.String("reaction", string(kind)))
+
+	delta, ok := reactionWeights[kind]
+	if !ok {
+		return recentity.ErrUnknownReaction
+	}
+
+	fact, err := r.factRepo.GetByID(ctx, factID)
+	if err != nil {
+		logger.LoggerFromContext(ctx).Info("ReactToFact: fact lookup failed", zap.Error(err))
+		return err
+	}
+
+	event := recentity.ReactionEvent{
+		UserID:    id,
+		FactID:    factID,
+		Category:  fact.Category,
+		Kind:      kind,
+		Dwell:     dwell,
+		CreatedAt: time.Now(),
+	}
+	if err := r.reactionRepo.Save(ctx, event); err != nil {
+		logger.LoggerFromContext(ctx).Error("Save reaction Error", zap.Error(err))
+		return err
+	}
+
+	r.rewardBandit(ctx, id, fact.Category, kind, dwell)
+
+	if dwell >= readDwell {
+		delta++
+	}
+	if delta == 0 {
+		return nil
+	}
+	if err := r.recRepo.Adjust(ctx, id, fact.Category, delta); err != nil {
+		logger.LoggerFromContext(ctx).Error("Adjust Error", zap.Error(err))
+		return err
+	}
+	if kind == recentity.ReactionLike {
+		r.propagate(id, fact.Category)
+	}
+	return nil
+}
+
+// RecordEvents проставляет событиям пользователя и категории фактов и передаёт их на запись.
+// Категории достаются одним GetByIDs; события по уже истёкшим фактам сохраняются без категории.
+func (r RecUseCaseImpl) RecordEvents(ctx context.Context, id entity.UserID, events []recentity.Event) error {
+	if r.eventSink == nil {
+		return ErrEventsDisabled
+	}
+	if len(events) > r.maxEvents {
+		return fmt.Errorf("%w: %d > %d", ErrTooManyEvents, len(events), r.maxEvents)
+	}
+
+	ids := make([]entity2.FactID, 0, len(events))
+	seen := make(map[entity2.FactID]struct{}, len(events))
+	for _, e := range events {
+		if _, ok := seen[e.FactID]; !ok {
+			seen[e.FactID] = struct{}{}
+			ids = append(ids, e.FactID)
+		}
+	}
+	facts, err := r.factRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		logger.LoggerFromContext(ctx).Error("GetByIDs Error", zap.Error(err))
+		return err
+	}
+	categories := make(map[entity2.FactID]entity2.Category, len(facts))
+	for _, f := range facts {
+		categories[f.ID] = f.Category
+	}
+
+	now := time.Now()
+	for i := range events {
+		events[i].UserID = id
+		events[i].Category = categories[events[i].FactID]
+		// часам клиента не доверяем настолько, чтобы принимать события из будущего
+		if events[i].OccurredAt.After(now) {
+			events[i].OccurredAt = now
+		}
+	}
+
+	return r.eventSink.Enqueue(events)
+}
+
+// ListInterestTopics возвращает темы онбординга на языке lang.
+func (r RecUseCaseImpl) ListInterestTopics(ctx context.Context, lang string) ([]recentity.InterestTopic, error) {
+	if r.interests == nil {
+		return nil, nil
+	}
+	return r.interests.Topics(ctx, lang), nil
+}
+
+// SetInterests засеивает интересы пользователя выбранными темами, чтобы персонализация
+// работала с первого запроса. Принимаются только темы каталога и их подкатегории.
+func (r RecUseCaseImpl) SetInterests(ctx context.Context, id entity.UserID, categories []entity2.Category) error {
+	logger.LoggerFromContext(ctx).Info("SetInterests usecase starts", zap.Int("categories", len(categories)))
+	if r.interests == nil {
+		return ErrUnknownInterest
+	}
+	for _, c := range categories {
+		if !r.interests.Contains(ctx, c) {
+			return fmt.Errorf("%w: %s", ErrUnknownInterest, c)
+		}
+	}
+
+	if err := r.recRepo.Seed(ctx, id, categories, r.interestWeight); err != nil {
+		logger.LoggerFromContext(ctx).Error("Seed Error", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// GetTrending возвращает тренды окна window (пусто — окно по умолчанию) и имя этого окна.
+// limit <= 0 или больше top_n ограничивается top_n.
+func (r RecUseCaseImpl) GetTrending(ctx context.Context, window string, limit int) (string, []recentity.TrendingCategory, error) {
+	if r.trendingStore == nil {
+		return "", nil, ErrTrendingDisabled
+	}
+	if window == "" {
+		window = r.trendingCfg.DefaultWindow
+	}
+	if _, ok := r.trendingCfg.Window(window); !ok {
+		return "", nil, fmt.Errorf("%w: %q", ErrUnknownWindow, window)
+	}
+	if limit <= 0 || limit > r.trendingCfg.TopN {
+		limit = r.trendingCfg.TopN
+	}
+
+	trending, err := r.trendingStore.Top(ctx, window, limit)
+	if err != nil {
+		logger.LoggerFromContext(ctx).Error("trending Top Error", zap.Error(err))
+		return "", nil, err
+	}
+	return window, trending, nil
+}
+
+// ChooseCategory балансирует любимые темы и пробу соседних: у каждой категории-кандидата
+// сэмплируется вероятность успеха показа, побеждает наибольшая. Без бандита или при ошибке
+// Redis выбирается первая рекомендация.
+func (r RecUseCaseImpl) ChooseCategory(ctx context.Context, id entity.UserID, candidates []recentity.RecommendedCategory) recentity.RecommendedCategory {
+	if r.bandit == nil || len(candidates) == 1 {
+		return candidates[0]
+	}
+
+	categories := make([]entity2.Category, len(candidates))
+	for i, c := range candidates {
+		categories[i] = c.Category
+	}
+	stats, err := r.banditStore.Stats(ctx, id, categories)
+	if err != nil {
+		logger.LoggerFromContext(ctx).Warn("bandit Stats Error", zap.Error(err))
+		return candidates[0]
+	}
+
+	arms := make([]bandit.Arm, len(candidates))
+	for i, c := range candidates {
+		st := stats[c.Category]
+		bonus := r.banditCfg.RankBonus * float64(len(candidates)-1-i) / float64(len(candidates)-1)
+		arms[i] = bandit.Arm{
+			Alpha: r.banditCfg.PriorAlpha + bonus + st.Successes,
+			Beta:  r.banditCfg.PriorBeta + st.Failures,
+		}
+	}
+	return candidates[r.bandit.Choose(arms)]
+}
+
+// rewardBandit учитывает реакцию в статистике бандита. Ошибка только логируется:
+// реакция уже сохранена, а потеря одной награды бандиту не критична.
+func (r RecUseCaseImpl) rewardBandit(ctx context.Context, id entity.UserID, category entity2.Category, kind recentity.ReactionKind, dwell time.Duration) {
+	if r.banditStore == nil {
+		return
+	}
+	reward := banditRewards[kind]
+	if kind == recentity.ReactionSkip && dwell >= readDwell {
+		reward = readRewardFloor
+	}
+	if err := r.banditStore.Reward(ctx, id, category, reward); err != nil {
+		logger.LoggerFromContext(ctx).Warn("bandit Reward Error", zap.Error(err))
+	}
+}
+
+// ListMyCategories возвращает страницу интересов пользователя и общее их число.
+func (r RecUseCaseImpl) ListMyCategories(ctx context.Context, id entity.UserID, limit, offset int) ([]recentity.CategoryPreference, int, error) {
+	prefs, total, err := r.recRepo.ListPreferences(ctx, id, limit, offset)
+	if err != nil {
+		logger.LoggerFromContext(ctx).Error("ListPreferences Error", zap.Error(err))
+		return nil, 0, err
+	}
+	return prefs, total, nil
+}
+
+// ResetCategory забывает скор, оценку и статистику бандита по категории.
+func (r RecUseCaseImpl) ResetCategory(ctx context.Context, id entity.UserID, category entity2.Category) error {
+	logger.LoggerFromContext(ctx).Info("ResetCategory usecase starts", zap.String("category", string(category)))
+	if err := r.recRepo.Reset(ctx, id, category); err != nil {
+		return err
+	}
+	if r.banditStore != nil {
+		if err := r.banditStore.Forget(ctx, id, category); err != nil {
+			logger.LoggerFromContext(ctx).Warn("bandit Forget Error", zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// ClearPreferences сбрасывает персонализацию: интересы и скоры категорий, лайки и состояние бандита.
+// Журнал событий не трогается — это аналитика, а не настройки пользователя; неявные сигналы
+// из него перестают влиять на выдачу, когда выходят из signal_window.
+func (r RecUseCaseImpl) ClearPreferences(ctx context.Context, id entity.UserID) error {
+	logger.LoggerFromContext(ctx).Info("ClearPreferences usecase starts")
+	if err := r.recRepo.Clear(ctx, id); err != nil {
+		logger.LoggerFromContext(ctx).Error("Clear Error", zap.Error(err))
+		return err
+	}
+	if r.banditStore != nil {
+		if err := r.banditStore.Clear(ctx, id); err != nil {
+			logger.LoggerFromContext(ctx).Warn("bandit Clear Error", zap.Error(err))
+		}
+	}
+	return nil
 }
@@ -0,0 +1,213 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/NordCoder/Story/services/recommendation/config"
+	recentity "github.com/NordCoder/Story/services/recommendation/entity"
+	"github.com/NordCoder/Story/services/recommendation/repository"
+)
+
+// ErrQueueFull возвращается, когда в очереди нет места под весь батч событий.
+var ErrQueueFull = errors.New("event queue is full")
+
+// shutdownFlushTimeout — сколько ждать записи остатка очереди при остановке.
+const shutdownFlushTimeout = 5 * time.Second
+
+// EventFlusher буферизует события в ограниченной очереди и асинхронно пишет их в Postgres.
+//
+// Доставка «хотя бы один раз»: при временной ошибке батч повторяется с растущей паузой, пока
+// запись не удастся, а повторы не создают дубликатов благодаря уникальному ID события. Пока
+// запись буксует, очередь заполняется и Enqueue начинает отказывать — клиент повторит отправку позже.
+// Ошибку данных (классы 22 и 23) повтор не исправит: батч делится пополам, пока плохие
+// события не останутся поодиночке, и отбрасываются только они. Отброшенные события логируются
+// и считаются в метрике; принятые в очередь, но не записанные к моменту падения процесса, теряются.
+type EventFlusher struct {
+	repo    repository.EventRepository
+	cfg     *config.EventsConfig
+	logger  *zap.Logger
+	dropped *prometheus.CounterVec
+
+	mu    sync.Mutex // резервирует место в очереди под батч целиком
+	queue chan recentity.Event
+}
+
+type EventFlusherOption func(*EventFlusher)
+
+// WithEventFlusherMetrics регистрирует счётчик отброшенных событий.
+func WithEventFlusherMetrics(reg prometheus.Registerer) EventFlusherOption {
+	return func(f *EventFlusher) {
+		f.dropped = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "wikifeed",
+				Subsystem: "events",
+				Name:      "dropped_total",
+				Help:      "Events dropped because Postgres rejected their data",
+			},
+			[]string{"reason"},
+		)
+		reg.MustRegister(f.dropped)
+	}
+}
+
+// dropInvalid — причина в метрике dropped_total: Postgres отверг данные события.
+const dropInvalid = "invalid"
+
+func NewEventFlusher(repo repository.EventRepository, cfg *config.EventsConfig, logger *zap.Logger, opts ...EventFlusherOption) *EventFlusher {
+	f := &EventFlusher{
+		repo:   repo,
+		cfg:    cfg,
+		logger: logger,
+		queue:  make(chan recentity.Event, cfg.QueueSize),
+	}
+	for _, o := range opts {
+		o(f)
+	}
+	return f
+}
+
+// Enqueue кладёт в очередь все события или ни одного.
+func (f *EventFlusher) Enqueue(events []recentity.Event) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Run только забирает из очереди, так что проверенное место не исчезнет до конца цикла
+	if cap(f.queue)-len(f.queue) < len(events) {
+		return ErrQueueFull
+	}
+	for _, e := range events {
+		f.queue <- e
+	}
+	return nil
+}
+
+// Run блокируется до отмены ctx; при остановке пытается записать остаток очереди.
+func (f *EventFlusher) Run(ctx context.Context) {
+	ticker := time.NewTicker(f.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]recentity.Event, 0, f.cfg.BatchSize)
+	for {
+		select {
+		case <-ctx.Done():
+			f.drain(batch)
+			return
+		case e := <-f.queue:
+			batch = append(batch, e)
+			if len(batch) < f.cfg.BatchSize {
+				continue
+			}
+		case <-ticker.C:
+			if len(batch) == 0 {
+				continue
+			}
+		}
+		// flush возвращает ошибку, только если ctx отменили посреди записи:
+		// батч целиком уходит в drain, уже записанные события повтор не задублирует
+		if err := f.flush(ctx, batch); err != nil {
+			f.drain(batch)
+			return
+		}
+		batch = batch[:0]
+	}
+}
+
+// flush записывает батч; отвергнутые Postgres события отбрасываются. При отмене ctx
+// недописанный батч возвращается вызывающему, чтобы его записал drain.
+func (f *EventFlusher) flush(ctx context.Context, batch []recentity.Event) error {
+	err := f.save(ctx, batch)
+	if isDataError(err) {
+		return f.split(ctx, batch, err)
+	}
+	return err
+}
+
+// save пишет батч, повторяя временные ошибки с растущей паузой, пока запись не удастся
+// или не будет отменён ctx. Ошибка данных возвращается сразу.
+func (f *EventFlusher) save(ctx context.Context, batch []recentity.Event) error {
+	backoff := f.cfg.RetryBackoff
+	for {
+		err := f.repo.SaveBatch(ctx, batch)
+		if err == nil || isDataError(err) {
+			return err
+		}
+		f.logger.Warn("failed to flush events, retrying", zap.Int("events", len(batch)), zap.Duration("backoff", backoff), zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, f.cfg.MaxRetryBackoff)
+	}
+}
+
+// split делит отвергнутый батч пополам и пишет половины по отдельности, так что отбрасываются
+// только сами плохие события.
+func (f *EventFlusher) split(ctx context.Context, batch []recentity.Event, err error) error {
+	if len(batch) == 1 {
+		f.drop(batch, dropInvalid, err)
+		return nil
+	}
+	mid := len(batch) / 2
+	if err := f.flush(ctx, batch[:mid]); err != nil {
+		return err
+	}
+	return f.flush(ctx, batch[mid:])
+}
+
+// drop логирует отброшенные события с их ID, чтобы их можно было найти, и учитывает в метрике.
+func (f *EventFlusher) drop(batch []recentity.Event, reason string, err error) {
+	ids := make([]string, len(batch))
+	for i, e := range batch {
+		ids[i] = e.ID
+	}
+	f.logger.Error("dropping events", zap.String("reason", reason), zap.Strings("event_ids", ids), zap.Error(err))
+	if f.dropped != nil {
+		f.dropped.WithLabelValues(reason).Add(float64(len(batch)))
+	}
+}
+
+// isDataError сообщает, что Postgres отверг сами данные (классы 22 и 23: неверный формат,
+// нарушение ограничений) и повтор того же батча ничего не изменит.
+func isDataError(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgerrcode.IsDataException(pgErr.Code) || pgerrcode.IsIntegrityConstraintViolation(pgErr.Code)
+}
+
+// drain пишет текущий батч и всё, что осталось в очереди, одной попыткой с таймаутом;
+// из отвергнутого Postgres батча, как и в flush, отбрасываются только плохие события.
+func (f *EventFlusher) drain(batch []recentity.Event) {
+	for len(f.queue) > 0 {
+		batch = append(batch, <-f.queue)
+	}
+	if len(batch) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownFlushTimeout)
+	defer cancel()
+	for start := 0; start < len(batch); start += f.cfg.BatchSize {
+		end := min(start+f.cfg.BatchSize, len(batch))
+		err := f.repo.SaveBatch(ctx, batch[start:end])
+		if isDataError(err) {
+			err = f.split(ctx, batch[start:end], err)
+		}
+		if err != nil {
+			f.logger.Error("failed to flush events on shutdown", zap.Int("lost", len(batch)-start), zap.Error(err))
+			return
+		}
+	}
+	f.logger.Info("event queue flushed on shutdown", zap.Int("events", len(batch)))
+}
@@ -0,0 +1,171 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap"
+
+	"github.com/NordCoder/Story/internal/entity"
+	authentity "github.com/NordCoder/Story/services/authorization/entity"
+	"github.com/NordCoder/Story/services/recommendation/config"
+	recentity "github.com/NordCoder/Story/services/recommendation/entity"
+)
+
+// fakeEventRepo отвергает батчи с событиями из bad как ошибку данных и первые transient
+// вызовов SaveBatch — как временную ошибку.
+type fakeEventRepo struct {
+	mu        sync.Mutex
+	bad       map[string]bool
+	transient int
+	calls     int
+	saved     []string
+}
+
+func (r *fakeEventRepo) SaveBatch(_ context.Context, events []recentity.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls++
+	if r.transient > 0 {
+		r.transient--
+		return errors.New("connection reset")
+	}
+	for _, e := range events {
+		if r.bad[e.ID] {
+			return &pgconn.PgError{Code: pgerrcode.InvalidTextRepresentation}
+		}
+	}
+	for _, e := range events {
+		r.saved = append(r.saved, e.ID)
+	}
+	return nil
+}
+
+func (r *fakeEventRepo) TopSignalCategories(context.Context, authentity.UserID, time.Time, int) ([]recentity.ScoredCategory, error) {
+	return nil, nil
+}
+
+func newTestFlusher(repo *fakeEventRepo) (*EventFlusher, *prometheus.CounterVec) {
+	cfg := &config.EventsConfig{
+		QueueSize:       100,
+		BatchSize:       10,
+		FlushInterval:   time.Second,
+		RetryBackoff:    time.Millisecond,
+		MaxRetryBackoff: time.Millisecond,
+	}
+	f := NewEventFlusher(repo, cfg, zap.NewNop(), WithEventFlusherMetrics(prometheus.NewRegistry()))
+	return f, f.dropped
+}
+
+func testEvents(ids ...string) []recentity.Event {
+	events := make([]recentity.Event, len(ids))
+	for i, id := range ids {
+		events[i] = recentity.Event{ID: id, FactID: entity.FactID(id)}
+	}
+	return events
+}
+
+func TestFlushDropsOnlyInvalidEvents(t *testing.T) {
+	repo := &fakeEventRepo{bad: map[string]bool{"c": true, "f": true}}
+	f, dropped := newTestFlusher(repo)
+
+	if err := f.flush(context.Background(), testEvents("a", "b", "c", "d", "e", "f", "g")); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"a", "b", "d", "e", "g"}; !equalIDs(repo.saved, want) {
+		t.Errorf("saved = %v, want %v", repo.saved, want)
+	}
+	if got := testutil.ToFloat64(dropped.WithLabelValues(dropInvalid)); got != 2 {
+		t.Errorf("dropped invalid = %v, want 2", got)
+	}
+}
+
+func TestFlushRetriesTransientErrorsUntilSaved(t *testing.T) {
+	// Postgres недоступен дольше, чем длится любая разумная серия повторов
+	repo := &fakeEventRepo{transient: 50}
+	f, dropped := newTestFlusher(repo)
+
+	if err := f.flush(context.Background(), testEvents("a", "b")); err != nil {
+		t.Fatal(err)
+	}
+
+	if !equalIDs(repo.saved, []string{"a", "b"}) || repo.calls != 51 {
+		t.Errorf("saved %v in %d calls, want both events after 50 failures", repo.saved, repo.calls)
+	}
+	if got := testutil.ToFloat64(dropped.WithLabelValues(dropInvalid)); got != 0 {
+		t.Errorf("dropped = %v, want 0: transient errors must not lose events", got)
+	}
+}
+
+func TestRunKeepsBatchWhenStoppedDuringRetries(t *testing.T) {
+	repo := &fakeEventRepo{transient: 1 << 30}
+	f, _ := newTestFlusher(repo)
+	f.cfg.BatchSize = 2
+	if err := f.Enqueue(testEvents("a", "b")); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		f.Run(ctx)
+		close(done)
+	}()
+
+	// ждём, пока Run застрянет в повторах, затем «поднимаем» Postgres и останавливаем Run
+	for {
+		repo.mu.Lock()
+		calls := repo.calls
+		if calls >= 3 {
+			repo.transient = 0
+		}
+		repo.mu.Unlock()
+		if calls >= 3 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	if !equalIDs(repo.saved, []string{"a", "b"}) {
+		t.Errorf("saved = %v, want the batch written by drain on shutdown", repo.saved)
+	}
+}
+
+func TestDrainSkipsInvalidEvents(t *testing.T) {
+	repo := &fakeEventRepo{bad: map[string]bool{"b": true}}
+	f, _ := newTestFlusher(repo)
+	if err := f.Enqueue(testEvents("c", "d")); err != nil {
+		t.Fatal(err)
+	}
+
+	f.drain(testEvents("a", "b"))
+
+	if want := []string{"a", "c", "d"}; !equalIDs(repo.saved, want) {
+		t.Errorf("saved = %v, want %v", repo.saved, want)
+	}
+}
+
+func equalIDs(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -1,12 +1,10 @@
 package worker
 
-//todo: we need config here
-
 import (
 	"context"
+	"math"
 	"sync"
 
-	"github.com/NordCoder/Story/internal/logger"
 	entity2 "github.com/NordCoder/Story/services/authorization/entity"
 
 	"go.uber.org/zap"
@@ -14,43 +12,63 @@ import (
 	"github.com/NordCoder/Story/internal/entity"
 	"github.com/NordCoder/Story/internal/infrastructure/wikipedia"
 
-	//"github.com/NordCoder/Story/services/recommendation/config"
+	"github.com/NordCoder/Story/services/recommendation/config"
 	"github.com/NordCoder/Story/services/recommendation/repository"
 )
 
+// PropagateTask — распространить интерес пользователя с Category на её подкатегории.
+// Depth 0 — сама лайкнутая категория.
 type PropagateTask struct {
 	UserID   entity2.UserID
 	Category entity.Category
 	Depth    int
 }
 
+// PropagationWorker раздаёт лайк категории её подкатегориям с весом decay_factor^глубина.
 type PropagationWorker struct {
 	repo       repository.RecRepository
-	wikiClient *wikipedia.Client
-	//cfg        config.RecommendationConfig
-	tasks chan PropagateTask
+	wikiClient wikipedia.WikiClient
+	cfg        *config.PropagationConfig
+	logger     *zap.Logger
+	tasks      chan PropagateTask
 }
 
 func NewPropagationWorker(
 	repo repository.RecRepository,
-	wikiClient *wikipedia.Client,
-	//cfg config.RecommendationConfig,
-	tasks chan PropagateTask,
+	wikiClient wikipedia.WikiClient,
+	cfg *config.PropagationConfig,
+	logger *zap.Logger,
 ) *PropagationWorker {
 	return &PropagationWorker{
 		repo:       repo,
 		wikiClient: wikiClient,
-		//cfg:        cfg,
-		tasks: tasks,
+		cfg:        cfg,
+		logger:     logger,
+		tasks:      make(chan PropagateTask, cfg.QueueSize),
+	}
+}
+
+// Enqueue ставит в очередь распространение лайка категории. Не блокируется:
+// при переполненной очереди задача отбрасывается и возвращается false.
+func (w *PropagationWorker) Enqueue(userID entity2.UserID, category entity.Category) bool {
+	return w.enqueue(PropagateTask{UserID: userID, Category: category})
+}
+
+func (w *PropagationWorker) enqueue(task PropagateTask) bool {
+	select {
+	case w.tasks <- task:
+		return true
+	default:
+		w.logger.Warn("tasks channel full, skipping enqueue", zap.String("category", string(task.Category)), zap.Int("depth", task.Depth))
+		return false
 	}
 }
 
+// Start запускает worker_count обработчиков и блокируется, пока ctx не отменён и все они не завершились.
 func (w *PropagationWorker) Start(ctx context.Context) {
 	var wg sync.WaitGroup
-	//wg.Add(w.cfg.WorkerCount)
-	wg.Add(10)
-	//for i := 0; i < w.cfg.WorkerCount; i++ {
-	for i := 0; i < 10; i++ {
+	wg.Add(w.cfg.WorkerCount)
+	for i := 0; i < w.cfg.WorkerCount; i++ {
 		go func() {
 			defer wg.Done()
 			for {
@@ -64,40 +82,35 @@ func (w *PropagationWorker) Start(ctx context.Context) {
 		}()
 	}
 	wg.Wait()
+	w.logger.Info("propagation worker stopped")
 }
 
 func (w *PropagationWorker) handleTask(ctx context.Context, task PropagateTask) {
-	//if task.Depth >= w.cfg.MaxDepth {
-	if task.Depth >= 1 {
+	if task.Depth >= w.cfg.MaxDepth {
 		return
 	}
 
-	//subs, err := w.wikiClient.GetSubcategories(ctx, task.Category, w.cfg.SubcatLimit)
-	subs, err := w.wikiClient.GetSubcategories(ctx, task.Category, 10)
+	subs, err := w.wikiClient.GetSubcategories(ctx, task.Category, w.cfg.SubcatLimit)
 	if err != nil {
-		logger.LoggerFromContext(ctx).Error("failed to get subcategories", zap.String("category", string(task.Category)), zap.Error(err))
+		w.logger.Error("failed to get subcategories", zap.String("category", string(task.Category)), zap.Error(err))
 		return
 	}
 
 	if len(subs) > 0 {
-		//weight := math.Pow(w.cfg.DecayFactor, float64(task.Depth))
-		//err := w.repo.BulkAdjust(ctx, task.UserID, subs, int(weight))
-		if err := w.repo.BulkAdjust(ctx, task.UserID, subs, 1); err != nil {
-			logger.LoggerFromContext(ctx).Error("failed to bulk adjust preferences", zap.Error(err), zap.String("category", string(task.Category)))
+		weight := math.Pow(w.cfg.DecayFactor, float64(task.Depth+1))
+		if err := w.repo.BulkAdjust(ctx, task.UserID, subs, weight); err != nil {
+			w.logger.Error("failed to bulk adjust preferences", zap.Error(err), zap.String("category", string(task.Category)))
 		}
 	}
 
+	if task.Depth+1 >= w.cfg.MaxDepth {
+		return
+	}
 	for _, sub := range subs {
-		nextTask := PropagateTask{
+		w.enqueue(PropagateTask{
 			UserID:   task.UserID,
 			Category: sub,
 			Depth:    task.Depth + 1,
-		}
-
-		select {
-		case w.tasks <- nextTask:
-		default:
-			logger.LoggerFromContext(ctx).Warn("tasks channel full, skipping enqueue", zap.String("subcategory", string(sub)))
-		}
+		})
 	}
 }
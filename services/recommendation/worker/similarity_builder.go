@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/NordCoder/Story/services/recommendation/config"
+	"github.com/NordCoder/Story/services/recommendation/repository"
+)
+
+// SimilarityBuilder периодически пересобирает модель похожих категорий.
+type SimilarityBuilder struct {
+	repo   repository.SimilarityRepository
+	cfg    *config.CollaborativeConfig
+	logger *zap.Logger
+}
+
+func NewSimilarityBuilder(repo repository.SimilarityRepository, cfg *config.CollaborativeConfig, logger *zap.Logger) *SimilarityBuilder {
+	return &SimilarityBuilder{
+		repo:   repo,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// Run пересобирает модель сразу и затем раз в refresh_interval, пока жив ctx.
+func (b *SimilarityBuilder) Run(ctx context.Context) {
+	b.rebuild(ctx)
+
+	ticker := time.NewTicker(b.cfg.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			b.logger.Info("similarity builder stopped")
+			return
+		case <-ticker.C:
+			b.rebuild(ctx)
+		}
+	}
+}
+
+func (b *SimilarityBuilder) rebuild(ctx context.Context) {
+	start := time.Now()
+	pairs, err := b.repo.Rebuild(ctx, b.cfg)
+	if errors.Is(err, repository.ErrRebuildInProgress) {
+		b.logger.Debug("category similarity is being rebuilt by another instance")
+		return
+	}
+	if err != nil {
+		b.logger.Error("failed to rebuild category similarity", zap.Error(err))
+		return
+	}
+	b.logger.Info("category similarity rebuilt", zap.Int64("pairs", pairs), zap.Duration("took", time.Since(start)))
+}
@@ -0,0 +1,68 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/NordCoder/Story/services/recommendation/config"
+	"github.com/NordCoder/Story/services/recommendation/repository"
+)
+
+// trendingTTLFactor — сколько интервалов пересчёта живут тренды в Redis без обновления.
+const trendingTTLFactor = 3
+
+// TrendingAggregator периодически пересчитывает тренды по всем окнам и складывает их в TrendingStore.
+// Пересчёт идемпотентен, поэтому несколько инстансов могут работать одновременно без координации.
+type TrendingAggregator struct {
+	repo   repository.TrendingRepository
+	store  repository.TrendingStore
+	cfg    *config.TrendingConfig
+	logger *zap.Logger
+}
+
+func NewTrendingAggregator(repo repository.TrendingRepository, store repository.TrendingStore, cfg *config.TrendingConfig, logger *zap.Logger) *TrendingAggregator {
+	return &TrendingAggregator{
+		repo:   repo,
+		store:  store,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// Run пересчитывает тренды сразу и затем раз в refresh_interval, пока жив ctx.
+func (a *TrendingAggregator) Run(ctx context.Context) {
+	a.Refresh(ctx)
+
+	ticker := time.NewTicker(a.cfg.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			a.logger.Info("trending aggregator stopped")
+			return
+		case <-ticker.C:
+			a.Refresh(ctx)
+		}
+	}
+}
+
+// Refresh пересчитывает все окна; ошибка одного окна не мешает остальным.
+func (a *TrendingAggregator) Refresh(ctx context.Context) {
+	now := time.Now()
+	ttl := a.cfg.RefreshInterval * trendingTTLFactor
+	for _, w := range a.cfg.Windows {
+		scores, err := a.repo.Scores(ctx, now.Add(-w.Duration), a.cfg.Weights, a.cfg.TopN)
+		if err != nil {
+			a.logger.Error("failed to compute trending categories", zap.String("window", w.Name), zap.Error(err))
+			continue
+		}
+		if err := a.store.Replace(ctx, w.Name, scores, ttl); err != nil {
+			a.logger.Error("failed to store trending categories", zap.String("window", w.Name), zap.Error(err))
+			continue
+		}
+		a.logger.Debug("trending categories refreshed", zap.String("window", w.Name), zap.Int("categories", len(scores)))
+	}
+}